@@ -8,13 +8,13 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"strconv"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
 	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/binder"
 )
 
 // simplified version of this.
@@ -47,17 +47,6 @@ type GetUserInput struct {
 	UserID int `json:"userId" in:"path"`
 }
 
-func (input *GetUserInput) Bind(req *http.Request) error {
-	v := chi.URLParam(req, "userId")
-	userID, err := strconv.ParseInt(v, 10, 0)
-	if err != nil {
-		return err
-	}
-	input.UserID = int(userID)
-
-	return nil
-}
-
 // GetUser returns user
 func GetUser(input GetUserInput) (User, error) {
 	userID := input.UserID
@@ -113,6 +102,9 @@ type APIError struct {
 }
 
 func Mount(s Setup) {
+	b := binder.New(nil)
+	b.PathValue = chi.URLParam
+
 	s.AddEndpoint(
 		"GET", "/users", ListUsers,
 		func(w http.ResponseWriter, req *http.Request) {
@@ -124,7 +116,7 @@ func Mount(s Setup) {
 		"GET", "/users/{userId}", GetUser,
 		func(w http.ResponseWriter, req *http.Request) {
 			var input GetUserInput
-			if err := input.Bind(req); err != nil {
+			if err := b.Bind(req, &input); err != nil {
 				render.Status(req, 400)
 				render.JSON(w, req, APIError{err.Error()})
 				return
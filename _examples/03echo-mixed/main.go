@@ -11,10 +11,8 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"reflect"
 	"regexp"
 	"strconv"
-	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-playground/validator/v10"
@@ -33,7 +31,7 @@ import (
 
 type User struct {
 	ID   int    `json:"id"`
-	Name string `json:"name" validate:"required"` // for go-playground/validator
+	Name string `json:"name" validate:"required,max=255"` // for go-playground/validator
 }
 
 type CustomValidator struct {
@@ -66,7 +64,7 @@ func InsertUser(user User) (User, error) {
 }
 
 type GetUserInput struct {
-	UserID int `json:"userId" in:"path"`
+	UserID int `json:"userId" in:"path" validate:"gt=0"`
 }
 
 // GetUser returns user
@@ -215,18 +213,12 @@ func run() error {
 
 	ctx := context.Background()
 	c := reflectopenapi.Config{
-		SkipValidation: false,
-		StrictSchema:   true,
-		DefaultError:   APIError{},
-		Info:           info.New(),
-		EnableAutoTag:  true,
-		IsRequiredCheckFunction: func(f reflect.StructTag) bool {
-			v, ok := f.Lookup("validate")
-			if !ok {
-				return false
-			}
-			return strings.Contains(v, "required")
-		},
+		SkipValidation:      false,
+		StrictSchema:        true,
+		DefaultError:        APIError{},
+		Info:                info.New(),
+		EnableAutoTag:       true,
+		ConstraintExtractor: &reflectopenapi.ValidatorConstraintExtractor{}, // for go-playground/validator
 	}
 
 	if !options.useDoc {
@@ -0,0 +1,165 @@
+// Code generated by github.com/podhmo/reflect-openapi/tsgen; DO NOT EDIT.
+
+export interface AddPetInput {
+  /** name of pet */
+  name: string;
+  /** tag of pet */
+  tag?: string;
+}
+
+export interface Error {
+  /** Error code */
+  code: number;
+  /** Error message */
+  message: string;
+}
+
+/** Pet is the pet */
+export interface Pet {
+  id: number;
+  /** name of pet */
+  name: string;
+  /** tag of pet */
+  tag?: string;
+  createdAt: Time;
+}
+
+export type Time = string;
+
+export interface Client {
+  baseURL: string;
+  /** if not set, globalThis.fetch is used */
+  fetch?: typeof fetch;
+  /** added to all requests (e.g. Authorization) */
+  headers?: Record<string, string>;
+}
+
+/** the error of the non-2xx response, the body is the parsed JSON (or the text) */
+export class ResponseError<T = unknown> extends globalThis.Error {
+  readonly status: number;
+  readonly body: T;
+
+  constructor(status: number, body: T) {
+    super(`unexpected response: ${status}`);
+    this.name = "ResponseError";
+    this.status = status;
+    this.body = body;
+  }
+}
+
+async function send(client: Client, method: string, path: string, query: URLSearchParams, headers: Record<string, string>, body: BodyInit | undefined, init?: RequestInit): Promise<Response> {
+  const qs = query.toString();
+  const url = client.baseURL.replace(/\/$/, "") + path + (qs ? "?" + qs : "");
+  return (client.fetch ?? fetch)(url, {
+    ...init,
+    method,
+    headers: { ...client.headers, ...headers, ...(init?.headers as Record<string, string> | undefined) },
+    body,
+  });
+}
+
+async function responseError<T>(res: Response): Promise<ResponseError<T>> {
+  const text = await res.text();
+  let body: unknown = text;
+  try {
+    body = JSON.parse(text);
+  } catch {
+    // not JSON
+  }
+  return new ResponseError<T>(res.status, body as T);
+}
+
+export interface FindPetsRequest {
+  /** tags to filter by */
+  tags?: string[];
+  /** maximum number of results to return */
+  limit?: number;
+}
+
+/**
+ * FindPets returns all pets
+ *
+ * GET /pets
+ *
+ * @throws {ResponseError<Error>} if the response is not 2xx
+ */
+export async function findPets(client: Client, input: FindPetsRequest, init?: RequestInit): Promise<Pet[]> {
+  const query = new URLSearchParams();
+  const headers: Record<string, string> = {};
+  for (const v of input.tags ?? []) {
+    query.append("tags", String(v));
+  }
+  if (input.limit !== undefined && input.limit !== null) {
+    query.append("limit", String(input.limit));
+  }
+  const res = await send(client, "GET", `/pets`, query, headers, undefined, init);
+  if (!res.ok) {
+    throw await responseError<Error>(res);
+  }
+  return (await res.json()) as Pet[];
+}
+
+export interface AddPetRequest {
+  body: AddPetInput;
+}
+
+/**
+ * AddPet creates a new pet in the store. Duplicates are allowed
+ *
+ * POST /pets
+ *
+ * @throws {ResponseError<Error>} if the response is not 2xx
+ */
+export async function addPet(client: Client, input: AddPetRequest, init?: RequestInit): Promise<Pet> {
+  const query = new URLSearchParams();
+  const headers: Record<string, string> = {};
+  headers["Content-Type"] = "application/json";
+  const res = await send(client, "POST", `/pets`, query, headers, JSON.stringify(input.body), init);
+  if (!res.ok) {
+    throw await responseError<Error>(res);
+  }
+  return (await res.json()) as Pet;
+}
+
+export interface DeletePetRequest {
+  /** ID of pet to delete */
+  id: number;
+}
+
+/**
+ * DeletePet deletes a single pet based on the ID supplied
+ *
+ * DELETE /pets/{id}
+ *
+ * @throws {ResponseError<Error>} if the response is not 2xx
+ */
+export async function deletePet(client: Client, input: DeletePetRequest, init?: RequestInit): Promise<void> {
+  const query = new URLSearchParams();
+  const headers: Record<string, string> = {};
+  const res = await send(client, "DELETE", `/pets/${encodeURIComponent(String(input.id))}`, query, headers, undefined, init);
+  if (!res.ok) {
+    throw await responseError<Error>(res);
+  }
+}
+
+export interface FindPetByIDRequest {
+  /** ID of pet to fetch */
+  id: number;
+}
+
+/**
+ * FindPetByID returns a pet based on a single ID
+ *
+ * GET /pets/{id}
+ *
+ * @throws {ResponseError<Error>} if the response is not 2xx
+ */
+export async function findPetByID(client: Client, input: FindPetByIDRequest, init?: RequestInit): Promise<Pet> {
+  const query = new URLSearchParams();
+  const headers: Record<string, string> = {};
+  const res = await send(client, "GET", `/pets/${encodeURIComponent(String(input.id))}`, query, headers, undefined, init);
+  if (!res.ok) {
+    throw await responseError<Error>(res);
+  }
+  return (await res.json()) as Pet;
+}
@@ -0,0 +1,254 @@
+// Code generated by github.com/podhmo/reflect-openapi/clientgen; DO NOT EDIT.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/podhmo/reflect-openapi/_examples/c00client/petstore"
+)
+
+// Client is the client of the API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // if nil, http.DefaultClient is used
+	Header     http.Header  // added to all requests (e.g. Authorization)
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: http.Header{}}
+}
+
+// ResponseError is the error of the non-2xx response.
+type ResponseError struct {
+	StatusCode int
+	Body       interface{} // the decoded body (e.g. *Error), if the response is documented
+	RawBody    []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), bytes.TrimSpace(e.RawBody))
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	for k, vs := range c.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return res, nil
+}
+
+func jsonBody(v interface{}) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode request body: %w", err)
+	}
+	return bytes.NewReader(b), nil
+}
+
+func decodeJSON(res *http.Response, out interface{}) error {
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
+}
+
+// decodeError returns the *ResponseError, the body is decoded into out (if out is not nil).
+func decodeError(res *http.Response, out interface{}) error {
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	resErr := &ResponseError{StatusCode: res.StatusCode, RawBody: b}
+	if out != nil && json.Unmarshal(b, out) == nil {
+		resErr.Body = out
+	}
+	return resErr
+}
+
+func toString(v interface{}) string {
+	if v, ok := v.(encoding.TextMarshaler); ok {
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// FindPetsRequest is the request of FindPets.
+type FindPetsRequest struct {
+	// tags to filter by
+	Tags []string // in:query "tags"
+	// maximum number of results to return
+	Limit *int32 // in:query "limit"
+}
+
+// FindPets returns all pets
+//
+// GET /pets
+func (c *Client) FindPets(ctx context.Context, input FindPetsRequest) ([]petstore.Pet, error) {
+	path := "/pets"
+	query := url.Values{}
+	for _, v := range input.Tags {
+		query.Add("tags", toString(v))
+	}
+	if input.Limit != nil {
+		query.Set("limit", toString(*input.Limit))
+	}
+	var body io.Reader
+	req, err := c.newRequest(ctx, "GET", path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		var out []petstore.Pet
+		if err := decodeJSON(res, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	default:
+		return nil, decodeError(res, &petstore.Error{})
+	}
+}
+
+// AddPetRequest is the request of AddPet.
+type AddPetRequest struct {
+	Body petstore.AddPetInput
+}
+
+// AddPet creates a new pet in the store. Duplicates are allowed
+//
+// POST /pets
+func (c *Client) AddPet(ctx context.Context, input AddPetRequest) (*petstore.Pet, error) {
+	path := "/pets"
+	query := url.Values{}
+	body, err := jsonBody(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(ctx, "POST", path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		var out petstore.Pet
+		if err := decodeJSON(res, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	default:
+		return nil, decodeError(res, &petstore.Error{})
+	}
+}
+
+// DeletePetRequest is the request of DeletePet.
+type DeletePetRequest struct {
+	// ID of pet to delete
+	ID int64 // in:path "id"
+}
+
+// DeletePet deletes a single pet based on the ID supplied
+//
+// DELETE /pets/{id}
+func (c *Client) DeletePet(ctx context.Context, input DeletePetRequest) error {
+	path := "/pets/" + url.PathEscape(toString(input.ID))
+	query := url.Values{}
+	var body io.Reader
+	req, err := c.newRequest(ctx, "DELETE", path, query, body)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 204:
+		return nil
+	default:
+		return decodeError(res, &petstore.Error{})
+	}
+}
+
+// FindPetByIDRequest is the request of FindPetByID.
+type FindPetByIDRequest struct {
+	// ID of pet to fetch
+	ID int64 // in:path "id"
+}
+
+// FindPetByID returns a pet based on a single ID
+//
+// GET /pets/{id}
+func (c *Client) FindPetByID(ctx context.Context, input FindPetByIDRequest) (*petstore.Pet, error) {
+	path := "/pets/" + url.PathEscape(toString(input.ID))
+	query := url.Values{}
+	var body io.Reader
+	req, err := c.newRequest(ctx, "GET", path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		var out petstore.Pet
+		if err := decodeJSON(res, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	case 404:
+		return nil, decodeError(res, &petstore.Error{})
+	default:
+		return nil, decodeError(res, &petstore.Error{})
+	}
+}
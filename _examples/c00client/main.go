@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/_examples/c00client/petstore"
+	"github.com/podhmo/reflect-openapi/clientgen"
+	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/tsgen"
+)
+
+var options struct {
+	DocFile string
+	TSFile  string
+}
+
+func main() {
+	flag.StringVar(&options.DocFile, "docfile", "", "write openapi doc file")
+	flag.StringVar(&options.TSFile, "tsfile", "", "write typescript client file")
+	flag.Parse()
+
+	if err := run(); err != nil {
+		log.Fatalf("!! %+v", err)
+	}
+}
+
+func run() error {
+	c := &reflectopenapi.Config{
+		Info:         info.New(), // need!
+		DefaultError: petstore.Error{},
+	}
+	tree, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Doc.Info.Title = "Swagger Petstore"
+		m.Doc.Info.Version = "1.0.0"
+
+		m.RegisterError(petstore.ErrNotFound, 404, "pet is not found")
+		m.Get("/pets", petstore.FindPets)
+		m.Post("/pets", petstore.AddPet)
+		m.Get("/pets/{id}", petstore.FindPetByID).Errors(petstore.ErrNotFound)
+		m.Delete("/pets/{id}", petstore.DeletePet)
+	})
+	if err != nil {
+		return fmt.Errorf("build: %w", err)
+	}
+
+	if options.DocFile != "" {
+		f, err := os.Create(options.DocFile)
+		if err != nil {
+			return fmt.Errorf("open openapi doc: %w", err)
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(tree); err != nil {
+			return fmt.Errorf("write openapi doc: %w", err)
+		}
+	}
+
+	if options.TSFile != "" {
+		f, err := os.Create(options.TSFile)
+		if err != nil {
+			return fmt.Errorf("open typescript file: %w", err)
+		}
+		defer f.Close()
+		if err := tsgen.Generate(f, tree, c.Info, nil); err != nil {
+			return fmt.Errorf("generate typescript: %w", err)
+		}
+	}
+
+	// the types of the petstore package are reused in the client package
+	if err := clientgen.Generate(os.Stdout, tree, c.Info, &clientgen.Config{
+		PackageName: "client",
+		PackagePath: "github.com/podhmo/reflect-openapi/_examples/c00client/client",
+	}); err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package petstore
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Error struct {
+	Code    int32  `json:"code"`    // Error code
+	Message string `json:"message"` // Error message
+}
+
+// Pet is the pet
+type Pet struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`          // name of pet
+	Tag       string    `json:"tag,omitempty"` // tag of pet
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var ErrNotFound = errors.New("not found")
+
+type FindPetsInput struct {
+	Tags  []string `json:"tags" in:"query"`            // tags to filter by
+	Limit int32    `json:"limit,omitempty" in:"query"` // maximum number of results to return
+}
+
+// FindPets returns all pets
+func FindPets(ctx context.Context, input FindPetsInput) ([]Pet, error) {
+	return nil, nil
+}
+
+type AddPetInput struct {
+	Name string `json:"name"`          // name of pet
+	Tag  string `json:"tag,omitempty"` // tag of pet
+}
+
+// AddPet creates a new pet in the store. Duplicates are allowed
+func AddPet(ctx context.Context, input AddPetInput) (*Pet, error) {
+	return nil, nil
+}
+
+type FindPetByIDInput struct {
+	ID int64 `json:"id" in:"path"` // ID of pet to fetch
+}
+
+// FindPetByID returns a pet based on a single ID
+func FindPetByID(ctx context.Context, input FindPetByIDInput) (*Pet, error) {
+	return nil, ErrNotFound
+}
+
+type DeletePetInput struct {
+	ID int64 `json:"id" in:"path"` // ID of pet to delete
+}
+
+type DeletePetOutput struct {
+	_ struct{} `status:"204"`
+}
+
+// DeletePet deletes a single pet based on the ID supplied
+func DeletePet(ctx context.Context, input DeletePetInput) (DeletePetOutput, error) {
+	return DeletePetOutput{}, nil
+}
@@ -18,10 +18,12 @@ import (
 
 var options struct {
 	DocFile string
+	SiteDir string
 }
 
 func main() {
 	flag.StringVar(&options.DocFile, "docfile", "", "write openapi doc file")
+	flag.StringVar(&options.SiteDir, "sitedir", "", "write static html site (index.html and doc.md) into the directory")
 	flag.Parse()
 
 	if err := run(); err != nil {
@@ -148,6 +150,11 @@ func run() error {
 	}
 
 	doc := docgen.Generate(tree, c.Info)
+	if options.SiteDir != "" {
+		if err := docgen.WriteSite(options.SiteDir, doc); err != nil {
+			return fmt.Errorf("write site: %w", err)
+		}
+	}
 	if err := docgen.WriteDoc(os.Stdout, doc); err != nil {
 		return fmt.Errorf("generate: %w", err)
 	}
@@ -155,21 +162,9 @@ func run() error {
 }
 
 func mount(m *reflectopenapi.Manager) {
-	m.RegisterFunc(FindPets).After(func(op *openapi3.Operation) {
-		m.Doc.AddOperation("/pets", "GET", op)
-		op.Tags = []string{"pet", "read"}
-	}).Example(200, "application/json", "", "sample output", []Pet{{ID: 1, NewPet: NewPet{Name: "foo", Tag: "A"}}, {ID: 2, NewPet: NewPet{Name: "bar", Tag: "A"}}, {ID: 3, NewPet: NewPet{Name: "boo", Tag: "B"}}})
-
-	m.RegisterFunc(AddPet).After(func(op *openapi3.Operation) {
-		m.Doc.AddOperation("/pets", "POST", op)
-		op.Tags = []string{"pet", "write"}
-	})
-	m.RegisterFunc(FindPetByID).After(func(op *openapi3.Operation) {
-		m.Doc.AddOperation("/pets/{id}", "GET", op)
-		op.Tags = []string{"pet", "read"}
-	})
-	m.RegisterFunc(DeletePet).After(func(op *openapi3.Operation) {
-		m.Doc.AddOperation("/pets/{id}", "DELETE", op)
-		op.Tags = []string{"pet", "write"}
-	}).Status(204)
+	m.Get("/pets", FindPets).Tags("pet", "read").Example(200, "application/json", "", "sample output", []Pet{{ID: 1, NewPet: NewPet{Name: "foo", Tag: "A"}}, {ID: 2, NewPet: NewPet{Name: "bar", Tag: "A"}}, {ID: 3, NewPet: NewPet{Name: "boo", Tag: "B"}}})
+
+	m.Post("/pets", AddPet).Tags("pet", "write")
+	m.Get("/pets/{id}", FindPetByID).Tags("pet", "read")
+	m.Delete("/pets/{id}", DeletePet).Tags("pet", "write").Status(204)
 }
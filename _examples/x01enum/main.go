@@ -20,8 +20,8 @@ type Todo struct {
 type SortOrder string
 
 const (
-	SortOrderDesc SortOrder = "desc"
-	SortOrderAsc  SortOrder = "asc"
+	SortOrderDesc SortOrder = "desc" // descending order
+	SortOrderAsc  SortOrder = "asc"  // ascending order
 )
 
 func GetTodo(params struct {
@@ -39,6 +39,7 @@ func ListTodo(params struct {
 func main() {
 	c := reflectopenapi.Config{
 		SkipValidation: true,
+		EnableAutoEnum: true, // detecting enum from the constants of SortOrder
 	}
 	if ok, _ := strconv.ParseBool(os.Getenv("WITHOUT_REF")); ok {
 		c.Resolver = &reflectopenapi.NoRefResolver{}
@@ -48,7 +49,7 @@ func main() {
 		m.RegisterFunc(ListTodo).After(func(op *openapi3.Operation) {
 			m.Doc.AddOperation("/todo", "GET", op)
 		})
-		m.RegisterType(SortOrderAsc).Enum(SortOrderDesc, SortOrderAsc)
+		m.RegisterType(SortOrderAsc).Default(SortOrderAsc) // the enum values are detected by EnableAutoEnum
 		m.RegisterFunc(GetTodo).After(func(op *openapi3.Operation) {
 			m.Doc.AddOperation("/todo/{id}", "GET", op)
 		})
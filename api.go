@@ -14,6 +14,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
 	shape "github.com/podhmo/reflect-shape"
 )
 
@@ -49,6 +50,11 @@ type TagNameOption struct {
 	OverrideTag    string
 	DescriptionTag string
 
+	DiscriminatorTag string
+	AccessModeTag    string // e.g. `openapi:"readonly"`, `openapi:"writeonly"`
+	ContentTypeTag   string // e.g. `content-type:"image/png"`, for the encoding of the form body
+	StatusTag        string // e.g. `status:"201"` on the marker field of the output struct
+
 	XNewTypeTag string
 }
 
@@ -60,6 +66,11 @@ func DefaultTagNameOption() *TagNameOption {
 		DescriptionTag: "description",
 		OverrideTag:    "openapi-override",
 		XNewTypeTag:    "x-go-type",
+
+		DiscriminatorTag: "discriminator",
+		AccessModeTag:    "openapi",
+		ContentTypeTag:   "content-type",
+		StatusTag:        "status",
 	}
 }
 
@@ -68,8 +79,9 @@ type Config struct {
 	Fset *token.FileSet
 	Info *info.Info // go/types.Info like object (tracking metadata)
 
-	Doc    *openapi3.T
-	Loaded bool // if true, skip registerType() and registerFunc() actions
+	Doc            *openapi3.T
+	Loaded         bool   // if true, skip registerType() and registerFunc() actions
+	OpenAPIVersion string // "3.0.0" (default), "3.1.0" or "2.0", used only by EmitDoc(). the doc is built as 3.0 (BuildDoc() returns it as is) and converted by ConvertToV31() or ConvertToV2() on emitting
 
 	Resolver  Resolver
 	Selector  Selector
@@ -81,12 +93,22 @@ type Config struct {
 
 	EnableAutoTag bool // if true, adding package name as tag
 
+	Security openapi3.SecurityRequirements // the global default security requirement, the schemes are registered by Manager.RegisterSecurityScheme()
+
 	DisableInputRef  bool
 	DisableOutputRef bool
+	SplitInputOutput bool        // if true, emitting XxxInput/XxxOutput components, if the schema has readOnly/writeOnly properties
+	SchemaNamer      SchemaNamer // naming strategy for components/schemas (e.g. for generics), default is DefaultSchemaNamer
+	EnableAutoEnum   bool        // if true, enum is detected from the typed constants in the package of the type (with go/packages, two or more constants are needed)
+	EmbeddedAsAllOf  bool        // if true, embedded structs are emitted as allOf (e.g. allOf: [{$ref: Base}, {...}]), or use `embedded:"allof"` tag per field
 
 	DefaultError            interface{}
 	DefaultErrorExample     interface{}
-	IsRequiredCheckFunction func(reflect.StructTag) bool // handling required, default is always false
+	ErrorCatalog            *ErrorCatalog                       // the table of the errors and the responses, used by RegisterFuncAction.Errors()
+	IsRequiredCheckFunction func(reflect.StructTag) bool        // handling required of parameters, default is always false (deprecated: use RequiredPolicy)
+	RequiredPolicy          RequiredPolicy                      // handling required of properties and parameters, default is DefaultRequiredPolicy
+	ConstraintExtractor     ConstraintExtractor                 // handling validation tags (e.g. &ValidatorConstraintExtractor{}), default is nil
+	JSONMarshalerHook       func(*shape.Shape) *openapi3.Schema // handling json.Marshaler types, if returns nil, the type is transformed as usual
 	GoPositionFunc          func(*token.FileSet, *shape.Func) string
 }
 
@@ -101,10 +123,14 @@ func (c *Config) DefaultResolver() Resolver {
 		NameStore:        NewNameStore(),
 		DisableInputRef:  c.DisableInputRef,
 		DisableOutputRef: c.DisableOutputRef,
+		SplitInputOutput: c.SplitInputOutput,
 	}
 	if c.Info != nil {
 		resolver.NameStore.info = c.Info
 	}
+	if c.SchemaNamer != nil {
+		resolver.NameStore.Namer = c.SchemaNamer
+	}
 	if c.StrictSchema {
 		ng := false
 		resolver.AdditionalPropertiesAllowed = &ng
@@ -168,11 +194,30 @@ func (c *Config) NewManager() (*Manager, func(ctx context.Context) error, error)
 	if c.IsRequiredCheckFunction != nil {
 		v.Transformer.IsRequired = c.IsRequiredCheckFunction
 	}
+	v.Transformer.EmbeddedAsAllOf = c.EmbeddedAsAllOf
+	if c.EnableAutoEnum {
+		v.Transformer.EnumLookup = NewEnumLookup(c.Fset)
+	}
+	if c.RequiredPolicy != nil {
+		v.Transformer.RequiredPolicy = c.RequiredPolicy
+	}
+	if c.ConstraintExtractor != nil {
+		v.Transformer.ConstraintExtractor = c.ConstraintExtractor
+	}
+	if c.JSONMarshalerHook != nil {
+		v.Transformer.JSONMarshalerHook = c.JSONMarshalerHook
+	}
+
+	if c.ErrorCatalog == nil {
+		c.ErrorCatalog = NewErrorCatalog()
+	}
 
 	m := &Manager{
-		Doc:      c.Doc,
-		Resolver: c.Resolver,
-		Visitor:  v,
+		Doc:          c.Doc,
+		Resolver:     c.Resolver,
+		Visitor:      v,
+		ErrorCatalog: c.ErrorCatalog,
+		defaultError: c.DefaultError,
 	}
 
 	return m, func(ctx context.Context) error {
@@ -274,9 +319,21 @@ func (c *Config) NewManager() (*Manager, func(ctx context.Context) error, error)
 			}
 		}
 
+		if c.Security != nil {
+			m.Doc.Security = c.Security
+		}
+		if err := validateSecurityRequirements(m.Doc); err != nil {
+			return err
+		}
+		if err := m.validateRoutes(); err != nil {
+			return err
+		}
+
 		if b, ok := c.Resolver.(Binder); ok {
 			b.BindSchemas(m.Doc)
 		}
+		v.Transformer.bindRecursiveSchemas(m.Doc)
+		v.Transformer.fixDiscriminatorMapping()
 
 		return doValidation()
 	}, nil
@@ -300,9 +357,27 @@ func (c *Config) EmitDoc(use func(m *Manager)) {
 	if err != nil {
 		panic(err)
 	}
+	var out interface{} = doc
+	switch c.OpenAPIVersion {
+	case OpenAPIVersion31:
+		converted, err := ConvertToV31(doc)
+		if err != nil {
+			panic(err)
+		}
+		out = converted
+	case OpenAPIVersion20:
+		converted, issues, err := ConvertToV2(doc)
+		if err != nil {
+			panic(err)
+		}
+		for _, issue := range issues {
+			log.Printf("[WARN]  swagger2.0: %s", issue)
+		}
+		out = converted
+	}
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
-	if err := enc.Encode(doc); err != nil {
+	if err := enc.Encode(out); err != nil {
 		panic(err)
 	}
 }
@@ -312,7 +387,11 @@ type Manager struct {
 	Resolver Resolver
 	Actions  []*registerAction
 
-	Doc *openapi3.T
+	Doc          *openapi3.T
+	ErrorCatalog *ErrorCatalog
+
+	defaultError interface{}
+	routes       []*route // registered by Route()
 }
 
 const (
@@ -328,6 +407,7 @@ type registerAction struct {
 
 type RegisterTypeAction struct {
 	*registerAction
+	ob     interface{}
 	before func(*shape.Shape)
 	after  func(*openapi3.Schema)
 }
@@ -379,9 +459,20 @@ func (a *RegisterTypeAction) Example(value interface{}) *RegisterTypeAction {
 	})
 }
 
+// RequiredPolicy overrides the RequiredPolicy for the fields of the type.
+func (a *RegisterTypeAction) RequiredPolicy(policy RequiredPolicy) *RegisterTypeAction {
+	rt := reflect.TypeOf(a.ob)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	a.Manager.Visitor.Transformer.requiredPolicyMap[rt] = policy // not in action, the type may be referenced by other types
+	return a
+}
+
 func (m *Manager) RegisterType(ob interface{}, modifiers ...func(*openapi3.SchemaRef)) *RegisterTypeAction {
 	var ac *RegisterTypeAction
 	ac = &RegisterTypeAction{
+		ob: ob,
 		registerAction: &registerAction{
 			Manager: m,
 			Phase:   phase1Action,
@@ -405,6 +496,38 @@ func (m *Manager) RegisterType(ob interface{}, modifiers ...func(*openapi3.Schem
 	return ac
 }
 
+type RegisterUnionAction struct {
+	*RegisterTypeAction
+	def *unionDef
+}
+
+// Discriminator sets the discriminator's property name. The mapping's keys are derived from the struct tag (e.g. `discriminator:"created"`) of the field, the field value of the registered implementation, or the type name.
+func (a *RegisterUnionAction) Discriminator(propertyName string) *RegisterUnionAction {
+	a.def.discriminator = propertyName
+	return a
+}
+
+// AnyOf uses anyOf instead of oneOf.
+func (a *RegisterUnionAction) AnyOf() *RegisterUnionAction {
+	a.def.anyOf = true
+	return a
+}
+
+// RegisterUnion registers the interface as the union type of the implementations. (e.g. m.RegisterUnion((*Event)(nil), Created{}, Deleted{}))
+func (m *Manager) RegisterUnion(iface interface{}, impls ...interface{}) *RegisterUnionAction {
+	rt := reflect.TypeOf(iface)
+	for rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("RegisterUnion: expected interface type but got %v", rt))
+	}
+
+	def := &unionDef{impls: impls}
+	m.Visitor.Transformer.unionMap[rt] = def // not in action, the interface may be referenced by other types
+	return &RegisterUnionAction{RegisterTypeAction: m.RegisterType(iface), def: def}
+}
+
 type RegisterFuncAction struct {
 	*registerAction
 	before func(*shape.Func)
@@ -444,9 +567,12 @@ func (a *RegisterFuncAction) Description(description string) *RegisterFuncAction
 }
 func (a *RegisterFuncAction) Status(code int) *RegisterFuncAction {
 	return a.After(func(op *openapi3.Operation) {
-		def, ok := op.Responses["200"]
-		if ok {
-			delete(op.Responses, "200")
+		// the success response is "200", or the status code derived from the output type (e.g. "201" for Created[T]).
+		// if several 2xx responses exist, the lowest one is used
+		if found, ok := oasutil.SuccessStatus(op.Responses); ok {
+			name := strconv.Itoa(found)
+			def := op.Responses[name]
+			delete(op.Responses, name)
 			op.Responses[strconv.Itoa(code)] = def
 		}
 	})
@@ -464,6 +590,30 @@ func (a *RegisterFuncAction) AnotherError(code int, value interface{}, descripti
 		op.AddResponse(code, openapi3.NewResponse().WithDescription(description).WithContent(openapi3.NewContentWithJSONSchemaRef(errSchema)))
 	})
 }
+
+// RequestContentType sets the content type of the request body (e.g. "multipart/form-data", "application/x-www-form-urlencoded").
+func (a *RegisterFuncAction) RequestContentType(mime string) *RegisterFuncAction {
+	return a.Before(func(fn *shape.Func) {
+		a.Manager.Visitor.Transformer.requestContentTypes[fn.Shape.Number] = mime
+	})
+}
+
+// Errors adds the error responses of the errors registered in ErrorCatalog (e.g. .Errors(ErrNotFound, ErrConflict)).
+func (a *RegisterFuncAction) Errors(errs ...error) *RegisterFuncAction {
+	return a.After(func(op *openapi3.Operation) {
+		responses, err := a.Manager.errorResponses(errs)
+		if err != nil {
+			if FORCE {
+				log.Printf("[WARN]  Errors: %+v, ignored...", err)
+				return
+			}
+			panic(fmt.Sprintf("Errors: %+v", err))
+		}
+		for code, ref := range responses {
+			op.Responses[code] = ref
+		}
+	})
+}
 func (a *RegisterFuncAction) Tags(tags ...string) *RegisterFuncAction {
 	return a.After(func(op *openapi3.Operation) {
 		op.Tags = append(op.Tags, tags...)
@@ -485,17 +635,17 @@ func (a *RegisterFuncAction) Headers(values ...Header) *RegisterFuncAction {
 				break
 			}
 		}
-		res := ref.Value
-		if res == nil {
+		if ref == nil || ref.Value == nil {
 			log.Printf("[INFO]  operation id=%q does not have 2xx response, ignored", op.OperationID)
 			return
 		}
+		res := ref.Value
 		if res.Headers == nil {
 			res.Headers = make(openapi3.Headers, len(values))
 		}
 		for _, v := range values {
 			p := new(openapi3.Parameter).WithDescription(v.Description).WithSchema(openapi3.NewStringSchema())
-			if p.Example != "" {
+			if v.Example != "" {
 				p.Example = v.Example
 			}
 			res.Headers[v.Name] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: *p}}
@@ -621,6 +771,11 @@ func (m *Manager) RegisterFuncText(fn interface{}, contentType string, modifiers
 	}, modifiers...)...)
 }
 
+// RegisterError registers the error to ErrorCatalog, the error is the sentinel value (e.g. ErrNotFound) or the zero value of the error type (e.g. &ValidationError{}).
+func (m *Manager) RegisterError(err error, code int, description string) *ErrorDef {
+	return m.ErrorCatalog.Register(err, code, description)
+}
+
 func (m *Manager) RegisterInterception(rt reflect.Type, intercept func(*shape.Shape) *openapi3.Schema) {
 	m.Visitor.Transformer.RegisterInterception(rt, intercept)
 }
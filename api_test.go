@@ -3,7 +3,15 @@ package reflectopenapi_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"reflect"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	reflectopenapi "github.com/podhmo/reflect-openapi"
@@ -603,3 +611,1503 @@ func TestAutoTag(t *testing.T) {
 		t.Errorf("%+v", err)
 	}
 }
+
+// Event is the union of events
+type Event interface{ isEvent() }
+
+type CreatedEvent struct {
+	Type string `json:"type" discriminator:"created"`
+	Name string `json:"name"`
+}
+
+func (CreatedEvent) isEvent() {}
+
+type DeletedEvent struct {
+	Type string `json:"type"`
+	ID   int    `json:"id"`
+}
+
+func (DeletedEvent) isEvent() {}
+
+type EventList struct {
+	Items []Event `json:"items"`
+}
+
+func TestRegisterUnion(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+	}
+
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterUnion((*Event)(nil), CreatedEvent{}, DeletedEvent{Type: "deleted"}).Discriminator("type")
+		m.RegisterType(EventList{})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+{
+  "CreatedEvent": {
+    "properties": {"name": {"type": "string"}, "type": {"type": "string"}},
+    "required": ["type", "name"],
+    "title": "CreatedEvent",
+    "type": "object"
+  },
+  "DeletedEvent": {
+    "properties": {"id": {"type": "integer"}, "type": {"type": "string"}},
+    "required": ["type", "id"],
+    "title": "DeletedEvent",
+    "type": "object"
+  },
+  "Event": {
+    "description": "Event is the union of events",
+    "discriminator": {
+      "propertyName": "type",
+      "mapping": {
+        "created": "#/components/schemas/CreatedEvent",
+        "deleted": "#/components/schemas/DeletedEvent"
+      }
+    },
+    "oneOf": [
+      {"$ref": "#/components/schemas/CreatedEvent"},
+      {"$ref": "#/components/schemas/DeletedEvent"}
+    ],
+    "title": "Event"
+  },
+  "EventList": {
+    "properties": {
+      "items": {"items": {"$ref": "#/components/schemas/Event"}, "type": "array"}
+    },
+    "required": ["items"],
+    "title": "EventList",
+    "type": "object"
+  }
+}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(doc.Components.Schemas).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`"%d %s"`, m.Amount, m.Currency)), nil
+}
+
+type Price struct {
+	Value Money  `json:"value"`
+	Min   *Money `json:"min"`           // nullable
+	Max   *Money `json:"max,omitempty"` // not nullable
+}
+
+// MoneyText has its own MarshalJSON, so MarshalText is not used for the wire format.
+type MoneyText Money
+
+func (m MoneyText) MarshalJSON() ([]byte, error) { return json.Marshal(Money(m)) }
+func (m MoneyText) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d %s", m.Amount, m.Currency)), nil
+}
+
+func TestJSONMarshalerHook(t *testing.T) {
+	moneySchema := openapi3.NewStringSchema().WithPattern(`^[0-9]+ [A-Z]{3}$`)
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+		Resolver:       &reflectopenapi.NoRefResolver{},
+		JSONMarshalerHook: func(s *shape.Shape) *openapi3.Schema {
+			if s.Type == reflect.TypeOf(Money{}) {
+				return moneySchema // the same value is returned, so it must not be modified
+			}
+			return nil
+		},
+	}
+
+	var got *openapi3.SchemaRef
+	_, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(Price{}, func(ref *openapi3.SchemaRef) { got = ref })
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `{"type": "object", "title": "Price", "properties": {
+		"value": {"type": "string", "pattern": "^[0-9]+ [A-Z]{3}$"},
+		"min": {"type": "string", "pattern": "^[0-9]+ [A-Z]{3}$", "nullable": true},
+		"max": {"type": "string", "pattern": "^[0-9]+ [A-Z]{3}$"}
+	}, "required": ["value"]}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(got).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+
+	t.Run("without hook", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+			Resolver:       &reflectopenapi.NoRefResolver{},
+		}
+
+		var got *openapi3.SchemaRef
+		_, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterType(struct {
+				Value MoneyText `json:"value"`
+			}{}, func(ref *openapi3.SchemaRef) { got = ref })
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		// MarshalJSON is prior to MarshalText, and it is transformed as usual without the hook
+		want := `{"type": "object", "properties": {"value": {
+			"type": "object",
+			"title": "MoneyText",
+			"description": "MoneyText has its own MarshalJSON, so MarshalText is not used for the wire format.",
+			"properties": {"Amount": {"type": "integer", "format": "int64"}, "Currency": {"type": "string"}},
+			"required": ["Amount", "Currency"]
+		}}, "required": ["value"]}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(got).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+}
+
+type WithTimestamp struct {
+	CreatedAt string `json:"createdAt"`
+}
+
+type WithNickname struct {
+	Nickname string `json:"nickname,omitempty"`
+}
+
+type Member struct {
+	Name string `json:"name"`
+	WithTimestamp
+	WithNickname `embedded:"false"`
+}
+
+type Guest struct {
+	WithTimestamp `embedded:"allof"`
+	Name          string       `json:"name"`
+	Inviter       WithNickname `json:"inviter" embedded:"allof"` // named field is nested, the tag is ignored
+}
+
+type CreateMemberInput struct {
+	WithTimestamp
+}
+
+type CreateMemberOutput struct {
+	WithTimestamp
+	Location string `json:"-" in:"header" header:"Location" required:"true"`
+}
+
+func CreateMember(input CreateMemberInput) CreateMemberOutput { return CreateMemberOutput{} }
+
+func TestEmbeddedAsAllOf(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation:  false,
+		Extractor:       shapeCfg,
+		EmbeddedAsAllOf: true,
+	}
+
+	got, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(Member{})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"Member": {
+			"type": "object",
+			"title": "Member",
+			"allOf": [
+				{"$ref": "#/components/schemas/WithTimestamp"},
+				{
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"nickname": {"type": "string"}
+					},
+					"required": ["name"]
+				}
+			]
+		},
+		"WithTimestamp": {
+			"type": "object",
+			"title": "WithTimestamp",
+			"properties": {
+				"createdAt": {"type": "string"}
+			},
+			"required": ["createdAt"]
+		}
+	}
+	`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(got.Components.Schemas).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: false,
+			Extractor:      shapeCfg,
+		}
+
+		got, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterType(Guest{})
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		want := `
+		{
+			"type": "object",
+			"title": "Guest",
+			"allOf": [
+				{"$ref": "#/components/schemas/WithTimestamp"},
+				{
+					"type": "object",
+					"properties": {
+						"name": {"type": "string"},
+						"inviter": {"$ref": "#/components/schemas/WithNickname"}
+					},
+					"required": ["name", "inviter"]
+				}
+			]
+		}
+		`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(got.Components.Schemas["Guest"]).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("request and response body", func(t *testing.T) {
+		got, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterFunc(CreateMember).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/members", "POST", op)
+			})
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		op := got.Paths["/members"].Post
+		want := `
+		{
+			"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/CreateMemberInput"}}}},
+			"response": {
+				"content": {"application/json": {"schema": {"$ref": "#/components/schemas/CreateMemberOutput"}}},
+				"description": "",
+				"headers": {
+					"Location": {"required": true, "schema": {"type": "string"}}
+				}
+			}
+		}
+		`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"requestBody": op.RequestBody,
+				"response":    op.Responses["200"],
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+}
+
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Children []*TreeNode `json:"children"`
+}
+
+func ListTree() []TreeNode { return nil }
+
+func TestRecursiveTypeWithoutRef(t *testing.T) {
+	cases := []struct {
+		msg    string
+		config reflectopenapi.Config
+	}{
+		{msg: "NoRefResolver", config: reflectopenapi.Config{Resolver: &reflectopenapi.NoRefResolver{}}},
+		{msg: "DisableOutputRef", config: reflectopenapi.Config{DisableOutputRef: true}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			c.config.Extractor = shapeCfg
+			got, err := c.config.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+				m.RegisterFunc(ListTree).After(func(op *openapi3.Operation) {
+					m.Doc.AddOperation("/tree", "GET", op)
+				})
+			})
+			if err != nil {
+				t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+			}
+
+			want := `
+			{
+				"components": {
+					"schemas": {
+						"TreeNode": {
+							"type": "object",
+							"title": "TreeNode",
+							"properties": {
+								"name": {"type": "string"},
+								"children": {"type": "array", "items": {"$ref": "#/components/schemas/TreeNode"}}
+							},
+							"required": ["name", "children"]
+						}
+					}
+				},
+				"responses": {
+					"200": {
+						"description": "",
+						"content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/TreeNode"}}}}
+					},
+					"default": {"description": ""}
+				}
+			}`
+			if err := jsonequal.NoDiff(
+				jsonequal.FromString(want).Named("want"),
+				jsonequal.From(map[string]interface{}{
+					"components": got.Components,
+					"responses":  got.Paths["/tree"].Get.Responses,
+				}).Named("got"),
+			); err != nil {
+				t.Errorf("%+v", err)
+			}
+		})
+	}
+}
+
+// Color is the color of the item
+type Color string
+
+const (
+	// ColorRed is red
+	ColorRed  Color = "red"
+	ColorBlue Color = "blue" // blue
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityHigh
+)
+
+// Unit has only one constant, so it is not enum
+type Unit string
+
+const UnitPixel Unit = "px"
+
+type Item struct {
+	Color      Color    `json:"color"`
+	Background Color    `json:"background" description:"the background color"`
+	Priority   Priority `json:"priority"`
+	Unit       Unit     `json:"unit"`
+}
+
+func TestAutoEnum(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: false,
+		Extractor:      shapeCfg,
+		EnableAutoEnum: true,
+	}
+
+	got, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(Item{})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"Color": {
+			"type": "string",
+			"title": "Color",
+			"description": "Color is the color of the item",
+			"enum": ["red", "blue"],
+			"x-enum-varnames": ["ColorRed", "ColorBlue"],
+			"x-enum-descriptions": ["ColorRed is red", "blue"],
+			"x-go-type": "github.com/podhmo/reflect-openapi_test.Color"
+		},
+		"Item": {
+			"type": "object",
+			"title": "Item",
+			"properties": {
+				"color": {"$ref": "#/components/schemas/Color"},
+				"background": {"$ref": "#/components/schemas/Color"},
+				"priority": {"$ref": "#/components/schemas/Priority"},
+				"unit": {"type": "string"}
+			},
+			"required": ["color", "background", "priority", "unit"]
+		},
+		"Priority": {
+			"type": "integer",
+			"title": "Priority",
+			"enum": [1, 2],
+			"x-enum-varnames": ["PriorityLow", "PriorityHigh"],
+			"x-go-type": "github.com/podhmo/reflect-openapi_test.Priority"
+		}
+	}
+	`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(got.Components.Schemas).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+
+	t.Run("without ref", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+			EnableAutoEnum: true,
+			Resolver:       &reflectopenapi.NoRefResolver{},
+		}
+
+		var got *openapi3.SchemaRef
+		_, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterType(Item{}, func(ref *openapi3.SchemaRef) { got = ref })
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		// the description of the field is not shared with the other fields of the same enum
+		want := `
+		{
+			"color": "Color is the color of the item",
+			"background": "the background color"
+		}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"color":      got.Value.Properties["color"].Value.Description,
+				"background": got.Value.Properties["background"].Value.Description,
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+}
+
+type Account struct {
+	ID        string    `json:"id" openapi:"readonly"`
+	Name      string    `json:"name"`
+	Password  string    `json:"password" openapi:"writeonly"`
+	CreatedAt time.Time `json:"createdAt" openapi:"readonly"`
+}
+
+func CreateAccount(input Account) Account { return input }
+
+func TestReadOnlyWriteOnly(t *testing.T) {
+	run := func(t *testing.T, c reflectopenapi.Config) *openapi3.T {
+		t.Helper()
+		c.Extractor = shapeCfg
+		c.SkipValidation = false
+		doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterFunc(CreateAccount).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/accounts", "POST", op)
+			})
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+		return doc
+	}
+
+	t.Run("default", func(t *testing.T) {
+		doc := run(t, reflectopenapi.Config{})
+		want := `
+		{
+			"type": "object",
+			"title": "Account",
+			"properties": {
+				"id": {"type": "string", "readOnly": true},
+				"name": {"type": "string"},
+				"password": {"type": "string", "writeOnly": true},
+				"createdAt": {"allOf": [{"$ref": "#/components/schemas/Time"}], "readOnly": true}
+			},
+			"required": ["id", "name", "password", "createdAt"]
+		}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(doc.Components.Schemas["Account"]).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("SplitInputOutput", func(t *testing.T) {
+		doc := run(t, reflectopenapi.Config{SplitInputOutput: true})
+		want := `
+		{
+			"input": {"$ref": "#/components/schemas/AccountInput"},
+			"output": {"$ref": "#/components/schemas/AccountOutput"},
+			"AccountInput": {
+				"type": "object",
+				"title": "AccountInput",
+				"properties": {
+					"name": {"type": "string"},
+					"password": {"type": "string", "writeOnly": true}
+				},
+				"required": ["name", "password"]
+			},
+			"AccountOutput": {
+				"type": "object",
+				"title": "AccountOutput",
+				"properties": {
+					"id": {"type": "string", "readOnly": true},
+					"name": {"type": "string"},
+					"createdAt": {"allOf": [{"$ref": "#/components/schemas/Time"}], "readOnly": true}
+				},
+				"required": ["id", "name", "createdAt"]
+			}
+		}`
+
+		op := doc.Paths["/accounts"].Post
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"input":         op.RequestBody.Value.Content.Get("application/json").Schema,
+				"output":        op.Responses.Get(200).Value.Content.Get("application/json").Schema,
+				"AccountInput":  doc.Components.Schemas["AccountInput"],
+				"AccountOutput": doc.Components.Schemas["AccountOutput"],
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("SplitInputOutput with generics", func(t *testing.T) {
+		cases := []struct {
+			namer  reflectopenapi.SchemaNamer
+			input  string
+			output string // Versioned has no writeOnly properties, so the output is not split
+		}{
+			{namer: reflectopenapi.CamelCaseSchemaNamer, input: "VersionedAccountListInput", output: "VersionedAccountList"},
+			{namer: reflectopenapi.UnderscoreSchemaNamer, input: "Versioned_AccountListInput", output: "Versioned_AccountList"},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.input, func(t *testing.T) {
+				cfg := reflectopenapi.Config{SplitInputOutput: true, SchemaNamer: c.namer, Extractor: shapeCfg}
+				doc, err := cfg.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+					m.RegisterFunc(UpdateVersioned).After(func(op *openapi3.Operation) {
+						m.Doc.AddOperation("/versioned", "PUT", op)
+					})
+				})
+				if err != nil {
+					t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+				}
+
+				op := doc.Paths["/versioned"].Put
+				if want, got := "#/components/schemas/"+c.input, op.RequestBody.Value.Content.Get("application/json").Schema.Ref; want != got {
+					t.Errorf("input: want %q, but got %q", want, got)
+				}
+				if want, got := "#/components/schemas/"+c.output, op.Responses.Get(200).Value.Content.Get("application/json").Schema.Ref; want != got {
+					t.Errorf("output: want %q, but got %q", want, got)
+				}
+				if ref, ok := doc.Components.Schemas[c.input]; !ok {
+					t.Errorf("%q is not found in components", c.input)
+				} else if _, ok := ref.Value.Properties["version"]; ok {
+					t.Errorf("%q must not have the readOnly property", c.input)
+				}
+			})
+		}
+	})
+
+	t.Run("SplitInputOutput with inline properties", func(t *testing.T) {
+		cfg := reflectopenapi.Config{SplitInputOutput: true, Extractor: shapeCfg}
+		doc, err := cfg.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterFunc(CreateSession).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/sessions", "POST", op)
+			})
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		want := `
+		{
+			"SessionInput": {
+				"type": "object",
+				"title": "SessionInput",
+				"properties": {
+					"user": {
+						"type": "object",
+						"properties": {
+							"name": {"type": "string"},
+							"password": {"type": "string", "writeOnly": true}
+						},
+						"required": ["name", "password"]
+					},
+					"tokens": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {"value": {"type": "string"}},
+							"required": ["value"]
+						}
+					}
+				},
+				"required": ["user", "tokens"]
+			},
+			"SessionOutput": {
+				"type": "object",
+				"title": "SessionOutput",
+				"properties": {
+					"user": {
+						"type": "object",
+						"properties": {"name": {"type": "string"}},
+						"required": ["name"]
+					},
+					"tokens": {
+						"type": "array",
+						"items": {
+							"type": "object",
+							"properties": {
+								"value": {"type": "string"},
+								"expiresAt": {"type": "string", "readOnly": true}
+							},
+							"required": ["value", "expiresAt"]
+						}
+					}
+				},
+				"required": ["user", "tokens"]
+			}
+		}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"SessionInput":  doc.Components.Schemas["SessionInput"],
+				"SessionOutput": doc.Components.Schemas["SessionOutput"],
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+}
+
+type Versioned[T any] struct {
+	Version int `json:"version" openapi:"readonly"`
+	Item    T   `json:"item"`
+}
+
+func UpdateVersioned(input Versioned[[]Account]) Versioned[[]Account] { return input }
+
+type Session struct {
+	User struct {
+		Name     string `json:"name"`
+		Password string `json:"password" openapi:"writeonly"`
+	} `json:"user"`
+	Tokens []struct {
+		Value     string `json:"value"`
+		ExpiresAt string `json:"expiresAt" openapi:"readonly"`
+	} `json:"tokens"`
+}
+
+func CreateSession(input Session) Session { return input }
+
+type LegacyUser struct {
+	Name     string  `json:"name"`
+	Nickname *string `json:"nickname"`
+}
+
+type UserWithLegacy struct {
+	Name   string     `json:"name,omitempty"`
+	Legacy LegacyUser `json:"legacy"`
+}
+
+func TestRequiredPolicyPerType(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+		RequiredPolicy: &reflectopenapi.OmitEmptyRequiredPolicy{},
+	}
+	got, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(UserWithLegacy{})
+		m.RegisterType(LegacyUser{}).RequiredPolicy(&reflectopenapi.TagRequiredPolicy{})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `{"UserWithLegacy": ["legacy"], "LegacyUser": null}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"UserWithLegacy": got.Components.Schemas["UserWithLegacy"].Value.Required,
+			"LegacyUser":     got.Components.Schemas["LegacyUser"].Value.Required,
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+type Pet struct {
+	Name string  `json:"name" openapi-override:"{'example': 'foo'}"`
+	Age  int     `json:"age" openapi-override:"{'minimum': 0, 'exclusiveMinimum': true}"`
+	Kind string  `json:"kind" openapi-override:"{'enum': ['pet']}"`
+	Memo *string `json:"memo" required:"true"`
+	Mark *string `json:"mark" required:"true" openapi-override:"{'enum': ['star']}"`
+}
+
+func NewPetHook(pet Pet) {}
+
+func TestConvertToV31(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: false,
+		Extractor:      shapeCfg,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(NewPetHook).After(func(op *openapi3.Operation) {
+			m.AddWebhook("newPet", "POST", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	got, err := reflectopenapi.ConvertToV31(doc)
+	if err != nil {
+		t.Fatalf("ConvertToV31(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"openapi": "3.1.0",
+		"jsonSchemaDialect": "https://spec.openapis.org/oas/3.1/dialect/base",
+		"webhooks": {
+			"newPet": {
+				"post": {
+					"operationId": "github.com/podhmo/reflect-openapi_test.NewPetHook",
+					"requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+					"responses": {"default": {"description": ""}}
+				}
+			}
+		},
+		"Pet": {
+			"type": "object",
+			"title": "Pet",
+			"properties": {
+				"name": {"type": "string", "examples": ["foo"]},
+				"age": {"type": "integer", "exclusiveMinimum": 0},
+				"kind": {"type": "string", "const": "pet"},
+				"memo": {"type": ["string", "null"]},
+				"mark": {"type": ["string", "null"], "enum": ["star", null]}
+			},
+			"required": ["name", "age", "kind", "memo", "mark"]
+		}
+	}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"openapi":           got["openapi"],
+			"jsonSchemaDialect": got["jsonSchemaDialect"],
+			"webhooks":          got["webhooks"],
+			"Pet":               got["components"].(map[string]interface{})["schemas"].(map[string]interface{})["Pet"],
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+type ListPetsInput struct {
+	Shelter   string  `json:"shelter" in:"path"`
+	Limit     *int    `json:"limit" in:"query" openapi-override:"{'minimum': 1}"`
+	SessionID string  `json:"sessionId" in:"cookie"`
+	Color     *string `json:"color" in:"query" openapi-override:"{'enum': ['red', 'blue']}"`
+}
+
+func ListPets(ListPetsInput) []Pet { return nil }
+
+func AddPet(pet Pet) *Pet { return nil }
+
+func TestConvertToV2(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(ListPets).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/shelters/{shelter}/pets", "GET", op)
+		})
+		m.RegisterFunc(AddPet).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/pets", "POST", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+	doc.Servers = append(doc.Servers, &openapi3.Server{URL: "https://api.example.com/v1"})
+
+	got, issues, err := reflectopenapi.ConvertToV2(doc)
+	if err != nil {
+		t.Fatalf("ConvertToV2(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"swagger": "2.0",
+		"host": "localhost:8888",
+		"basePath": "",
+		"schemes": ["https", "http"],
+		"definitions": ["Pet"],
+		"listPets": {
+			"operationId": "github.com/podhmo/reflect-openapi_test.ListPets",
+			"parameters": [
+				{"in": "query", "name": "color", "type": "string", "enum": ["red", "blue"]},
+				{"in": "query", "name": "limit", "type": "integer", "minimum": 1},
+				{"in": "path", "name": "shelter", "type": "string", "required": true}
+			],
+			"produces": ["application/json"],
+			"responses": {
+				"200": {"description": "OK", "schema": {"type": "array", "items": {"$ref": "#/definitions/Pet"}}},
+				"default": {"description": "default"}
+			}
+		},
+		"addPet": {
+			"operationId": "github.com/podhmo/reflect-openapi_test.AddPet",
+			"consumes": ["application/json"],
+			"parameters": [
+				{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/Pet"}}
+			],
+			"produces": ["application/json"],
+			"responses": {
+				"200": {"description": "OK", "schema": {"$ref": "#/definitions/Pet"}},
+				"default": {"description": "default"}
+			}
+		},
+		"issues": [
+			"#/servers: multiple servers are not supported, only the first one (http://localhost:8888) is used as host and basePath",
+			"#/paths/~1shelters~1{shelter}~1pets/get/parameters/2: cookie parameter \"sessionId\" is not supported, dropped"
+		]
+	}`
+
+	definitions := make([]string, 0, len(got.Definitions))
+	for name := range got.Definitions {
+		definitions = append(definitions, name)
+	}
+	issueStrings := make([]string, len(issues))
+	for i, issue := range issues {
+		issueStrings[i] = issue.String()
+	}
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"swagger":     got.Swagger,
+			"host":        got.Host,
+			"basePath":    got.BasePath,
+			"schemes":     got.Schemes,
+			"definitions": definitions,
+			"listPets":    got.Paths["/shelters/{shelter}/pets"].Get,
+			"addPet":      got.Paths["/pets"].Post,
+			"issues":      issueStrings,
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+
+	// the original doc is not modified
+	if _, ok := doc.Components.Schemas["Pet"].Value.Properties["memo"].Value.Extensions["x-nullable"]; ok {
+		t.Errorf("ConvertToV2() modifies the original doc")
+	}
+}
+
+type UploadAvatarInput struct {
+	UserID  string                `json:"userId" in:"path"`
+	Avatar  *multipart.FileHeader `json:"-" in:"form" form:"avatar" content-type:"image/png, image/jpeg"`
+	Caption string                `json:"caption,omitempty" in:"form"`
+}
+
+func UploadAvatar(UploadAvatarInput) {}
+
+type ImportCSVInput struct {
+	File   io.Reader `json:"file"`
+	DryRun bool      `json:"dryRun"`
+}
+
+func ImportCSV(ImportCSVInput) {}
+
+type LoginInput struct {
+	Name     string `json:"name" in:"form"`
+	Password string `json:"password" in:"form"`
+}
+
+func Login(LoginInput) {}
+
+type SearchInput struct {
+	Query string `json:"query"`
+}
+
+func Search(SearchInput) {}
+
+func TestFormBody(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation:   true,
+		Extractor:        shapeCfg,
+		DisableInputRef:  true,
+		DisableOutputRef: true,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(UploadAvatar).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/users/{userId}/avatar", "PUT", op)
+		})
+		m.RegisterFunc(ImportCSV).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/import", "POST", op)
+		})
+		m.RegisterFunc(Login).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/login", "POST", op)
+		})
+		m.RegisterFunc(Search).RequestContentType("application/x-www-form-urlencoded").After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/search", "POST", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"uploadAvatar": {
+			"multipart/form-data": {
+				"schema": {
+					"type": "object",
+					"title": "UploadAvatarInput",
+					"properties": {
+						"avatar": {"type": "string", "format": "binary"},
+						"caption": {"type": "string"}
+					},
+					"x-go-id": "github.com/podhmo/reflect-openapi_test.UploadAvatarInput"
+				},
+				"encoding": {"avatar": {"contentType": "image/png, image/jpeg"}}
+			}
+		},
+		"importCSV": {
+			"multipart/form-data": {
+				"schema": {
+					"type": "object",
+					"title": "ImportCSVInput",
+					"properties": {
+						"file": {"type": "string", "format": "binary"},
+						"dryRun": {"type": "boolean", "default": false}
+					},
+					"required": ["file", "dryRun"],
+					"x-go-id": "github.com/podhmo/reflect-openapi_test.ImportCSVInput"
+				},
+				"encoding": {"file": {"contentType": "application/octet-stream"}}
+			}
+		},
+		"login": {
+			"application/x-www-form-urlencoded": {
+				"schema": {
+					"type": "object",
+					"title": "LoginInput",
+					"properties": {
+						"name": {"type": "string"},
+						"password": {"type": "string"}
+					},
+					"required": ["name", "password"],
+					"x-go-id": "github.com/podhmo/reflect-openapi_test.LoginInput"
+				}
+			}
+		},
+		"search": {
+			"application/x-www-form-urlencoded": {
+				"schema": {
+					"type": "object",
+					"title": "SearchInput",
+					"properties": {
+						"query": {"type": "string"}
+					},
+					"required": ["query"],
+					"x-go-id": "github.com/podhmo/reflect-openapi_test.SearchInput"
+				}
+			}
+		}
+	}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"uploadAvatar": doc.Paths["/users/{userId}/avatar"].Put.RequestBody.Value.Content,
+			"importCSV":    doc.Paths["/import"].Post.RequestBody.Value.Content,
+			"login":        doc.Paths["/login"].Post.RequestBody.Value.Content,
+			"search":       doc.Paths["/search"].Post.RequestBody.Value.Content,
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+func TestFormBodyV2(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(UploadAvatar).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/users/{userId}/avatar", "PUT", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+	got, _, err := reflectopenapi.ConvertToV2(doc)
+	if err != nil {
+		t.Fatalf("ConvertToV2(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"consumes": ["multipart/form-data"],
+		"parameters": [
+			{"in": "formData", "name": "avatar", "type": "file"},
+			{"in": "formData", "name": "caption", "type": "string"},
+			{"in": "path", "name": "userId", "type": "string", "required": true}
+		]
+	}`
+	op := got.Paths["/users/{userId}/avatar"].Put
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"consumes":   op.Consumes,
+			"parameters": op.Parameters,
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+type ListPetsOutput struct {
+	// total count of pets
+	TotalCount int    `json:"-" in:"header" header:"X-Total-Count"`
+	ETag       string `json:"-" in:"header" header:"ETag" description:"etag of the list" required:"true"`
+
+	Items []Pet `json:"items"`
+}
+
+func ListPetsWithCount() *ListPetsOutput { return nil }
+
+type CreatedOutput struct {
+	Location string `json:"-" in:"header" header:"Location" required:"true"`
+}
+
+func CreatePet(pet Pet) CreatedOutput { return CreatedOutput{} }
+
+func TestResponseHeaders(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(ListPetsWithCount).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/pets", "GET", op)
+		}).Headers(reflectopenapi.Header{Name: "X-Request-Id", Description: "request id", Example: "xxx"})
+		m.RegisterFunc(CreatePet).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/pets", "POST", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"list": {
+			"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ListPetsOutput"}}},
+			"description": "",
+			"headers": {
+				"X-Total-Count": {"description": "total count of pets", "schema": {"type": "integer"}},
+				"ETag": {"description": "etag of the list", "required": true, "schema": {"type": "string"}},
+				"X-Request-Id": {"description": "request id", "example": "xxx", "schema": {"type": "string"}}
+			}
+		},
+		"listBody": ["items"],
+		"create": {
+			"description": "",
+			"headers": {
+				"Location": {"required": true, "schema": {"type": "string"}}
+			}
+		}
+	}`
+
+	listBody := make([]string, 0)
+	for name := range doc.Components.Schemas["ListPetsOutput"].Value.Properties {
+		listBody = append(listBody, name)
+	}
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"list":     doc.Paths["/pets"].Get.Responses["200"],
+			"listBody": listBody,
+			"create":   doc.Paths["/pets"].Post.Responses["200"],
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
+
+type Job struct {
+	ID string `json:"id"`
+}
+
+type DeletedOutput struct {
+	_ struct{} `status:"204"`
+}
+
+type RedirectOutput struct {
+	Location string `json:"-" in:"header" header:"Location" required:"true"`
+}
+
+func (*RedirectOutput) StatusCode() int { return 303 }
+
+func TestStatusCodeFromOutput(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(func(pet Pet) reflectopenapi.Created[Pet] { return reflectopenapi.Created[Pet]{} }).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/pets", "POST", op)
+		})
+		m.RegisterFunc(func() (*reflectopenapi.Accepted[Job], error) { return nil, nil }).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/jobs", "POST", op)
+		})
+		m.RegisterFunc(func() DeletedOutput { return DeletedOutput{} }).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/pets/{id}", "DELETE", op)
+		})
+		m.RegisterFunc(func() RedirectOutput { return RedirectOutput{} }).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/redirect", "GET", op)
+		})
+		m.RegisterFunc(func() reflectopenapi.Created[Job] { return reflectopenapi.Created[Job]{} }).Status(200).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/jobs", "PUT", op)
+		})
+		m.RegisterFunc(func() reflectopenapi.Created[Job] { return reflectopenapi.Created[Job]{} }).AnotherError(202, Job{}, "queued").Status(200).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/jobs/{id}", "PUT", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `
+	{
+		"createPet": {
+			"201": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}, "description": ""},
+			"default": {"description": ""}
+		},
+		"createJob": {
+			"202": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}, "description": ""},
+			"default": {"description": ""}
+		},
+		"deletePet": {
+			"204": {"description": ""},
+			"default": {"description": ""}
+		},
+		"redirect": {
+			"303": {"description": "", "headers": {"Location": {"required": true, "schema": {"type": "string"}}}},
+			"default": {"description": ""}
+		},
+		"updateJob": {
+			"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}, "description": ""},
+			"default": {"description": ""}
+		},
+		"updateJobWithAnotherSuccess": {
+			"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}, "description": ""},
+			"202": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Job"}}}, "description": "queued"},
+			"default": {"description": ""}
+		}
+	}`
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(map[string]interface{}{
+			"createPet": doc.Paths["/pets"].Post.Responses,
+			"createJob": doc.Paths["/jobs"].Post.Responses,
+			"deletePet": doc.Paths["/pets/{id}"].Delete.Responses,
+			"redirect":  doc.Paths["/redirect"].Get.Responses,
+			"updateJob": doc.Paths["/jobs"].Put.Responses,
+
+			"updateJobWithAnotherSuccess": doc.Paths["/jobs/{id}"].Put.Responses,
+		}).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+
+	b, err := json.Marshal(reflectopenapi.Created[Job]{Body: Job{ID: "1"}})
+	if err != nil {
+		t.Fatalf("json.Marshal(): unexpected error: %+v", err)
+	}
+	if want, got := `{"id":"1"}`, string(b); want != got {
+		t.Errorf("json.Marshal(Created[Job]): want %s, but got %s", want, got)
+	}
+}
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
+)
+
+type ValidationError struct {
+	Field string
+}
+
+func (e *ValidationError) Error() string { return "invalid " + e.Field }
+
+type ErrorResponse struct {
+	Message string `json:"message"`
+}
+
+type ValidationErrorResponse struct {
+	Message string   `json:"message"`
+	Fields  []string `json:"fields"`
+}
+
+func TestErrorCatalog(t *testing.T) {
+	catalog := reflectopenapi.NewErrorCatalog()
+	catalog.Register(ErrNotFound, 404, "pet is not found").WithExample(ErrorResponse{Message: "not found"})
+	catalog.Register(ErrConflict, 409, "pet is already existed")
+	catalog.Register(&ValidationError{}, 400, "validation error").WithBody(ValidationErrorResponse{})
+
+	t.Run("doc", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+			DefaultError:   ErrorResponse{},
+			ErrorCatalog:   catalog,
+		}
+		doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterError(&net.DNSError{}, 503, "dns error").WithBody(ErrorResponse{})
+
+			m.RegisterFunc(AddPet).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/pets", "POST", op)
+			}).Errors(ErrConflict, &ValidationError{})
+			m.RegisterFunc(func() *Pet { return nil }).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/pets/{id}", "GET", op)
+			}).Errors(ErrNotFound, &net.DNSError{})
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		want := `
+		{
+			"addPet": {
+				"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}, "description": ""},
+				"400": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ValidationErrorResponse"}}}, "description": "validation error"},
+				"409": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}, "description": "pet is already existed"},
+				"default": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}, "description": "default error"}
+			},
+			"getPet": {
+				"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}, "description": ""},
+				"404": {
+					"content": {"application/json": {
+						"schema": {"$ref": "#/components/schemas/ErrorResponse"},
+						"examples": {"not found": {"summary": "pet is not found", "value": {"message": "not found"}}}
+					}},
+					"description": "pet is not found"
+				},
+				"503": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}, "description": "dns error"},
+				"default": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}, "description": "default error"}
+			}
+		}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"addPet": doc.Paths["/pets"].Post.Responses,
+				"getPet": doc.Paths["/pets/{id}"].Get.Responses,
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		cases := []struct {
+			msg  string
+			err  error
+			code int
+		}{
+			{msg: "sentinel", err: ErrNotFound, code: 404},
+			{msg: "wrapped sentinel", err: fmt.Errorf("find pet: %w", ErrConflict), code: 409},
+			{msg: "type", err: &ValidationError{Field: "name"}, code: 400},
+			{msg: "wrapped type", err: fmt.Errorf("create pet: %w", &ValidationError{Field: "name"}), code: 400},
+			{msg: "unregistered", err: errors.New("not found"), code: 0},
+		}
+		for _, c := range cases {
+			c := c
+			t.Run(c.msg, func(t *testing.T) {
+				code := 0
+				if def, ok := catalog.Lookup(c.err); ok {
+					code = def.Code
+				}
+				if code != c.code {
+					t.Errorf("Lookup(%v): want %d, but got %d", c.err, c.code, code)
+				}
+			})
+		}
+	})
+}
+
+func TestSecurity(t *testing.T) {
+	newConfig := func() *reflectopenapi.Config {
+		return &reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+			Security:       openapi3.SecurityRequirements{{"bearerAuth": []string{}}},
+		}
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		doc, err := newConfig().BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme())
+			m.RegisterSecurityScheme("session", openapi3.NewSecurityScheme().WithType("apiKey").WithIn("cookie").WithName("SESSION"))
+			m.RegisterSecurityScheme("oauth2", &openapi3.SecurityScheme{Type: "oauth2", Flows: &openapi3.OAuthFlows{
+				AuthorizationCode: &openapi3.OAuthFlow{
+					AuthorizationURL: "https://example.com/oauth/authorize",
+					TokenURL:         "https://example.com/oauth/token",
+					Scopes:           map[string]string{"read:pets": "read pets", "write:pets": "write pets"},
+				},
+			}})
+
+			m.RegisterFunc(ListPets).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/shelters/{shelter}/pets", "GET", op)
+			})
+			m.RegisterFunc(AddPet).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/pets", "POST", op)
+			}).Security("oauth2", "write:pets").Security("session")
+			m.RegisterFunc(func() string { return "" }).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/ping", "GET", op)
+			}).NoSecurity()
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		want := `
+		{
+			"global": [{"bearerAuth": []}],
+			"schemes": ["bearerAuth", "oauth2", "session"],
+			"listPets": null,
+			"addPet": [{"oauth2": ["write:pets"]}, {"session": []}],
+			"ping": []
+		}`
+		schemes := make([]string, 0, len(doc.Components.SecuritySchemes))
+		for name := range doc.Components.SecuritySchemes {
+			schemes = append(schemes, name)
+		}
+		sort.Strings(schemes)
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"global":   doc.Security,
+				"schemes":  schemes,
+				"listPets": doc.Paths["/shelters/{shelter}/pets"].Get.Security,
+				"addPet":   doc.Paths["/pets"].Post.Security,
+				"ping":     doc.Paths["/ping"].Get.Security,
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("missing scheme", func(t *testing.T) {
+		_, err := newConfig().BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.RegisterSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme())
+			m.RegisterFunc(AddPet).After(func(op *openapi3.Operation) {
+				m.Doc.AddOperation("/pets", "POST", op)
+			}).Security("oauth2", "write:pets")
+		})
+		if err == nil {
+			t.Fatalf("c.BuildDoc(): must be error, but nil")
+		}
+		if want, got := `security scheme is not found: "oauth2" in POST /pets (github.com/podhmo/reflect-openapi_test.AddPet)`, err.Error(); want != got {
+			t.Errorf("c.BuildDoc(): unexpected error\nwant: %s\ngot:  %s", want, got)
+		}
+	})
+}
+
+type GetPetInput struct {
+	PetID string `json:"petId" in:"path"`
+}
+
+func GetPet(GetPetInput) *Pet { return nil }
+
+func TestRoute(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+		}
+		doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.Get("/shelters/{shelter}/pets", ListPets)
+			m.Post("/pets", AddPet).Tags("pet")
+			m.Route("get", "/pets/{petId}", GetPet)
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		want := `
+		{
+			"listPets": "github.com/podhmo/reflect-openapi_test.ListPets",
+			"addPet": ["pet"],
+			"getPet": [{"in": "path", "name": "petId", "required": true, "schema": {"type": "string"}}]
+		}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(map[string]interface{}{
+				"listPets": doc.Paths["/shelters/{shelter}/pets"].Get.OperationID,
+				"addPet":   doc.Paths["/pets"].Post.Tags,
+				"getPet":   doc.Paths["/pets/{petId}"].Get.Parameters,
+			}).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		c := reflectopenapi.Config{
+			SkipValidation: true,
+			Extractor:      shapeCfg,
+		}
+		_, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.Get("/pets/{petID}", GetPet) // typo
+			m.Delete("/pets/{id}", func() {})
+		})
+		if err == nil {
+			t.Fatalf("c.BuildDoc(): must be error, but nil")
+		}
+		want := `path template mismatch: ` +
+			`path parameter "id" is not found in the input of DELETE /pets/{id} (github.com/podhmo/reflect-openapi_test.TestRoute.func2.1.1), ` +
+			`path parameter "petID" is not found in the input of GET /pets/{petID} (github.com/podhmo/reflect-openapi_test.GetPet), ` +
+			`path parameter "petId" is not found in the path template of GET /pets/{petID} (github.com/podhmo/reflect-openapi_test.GetPet)`
+		if got := err.Error(); want != got {
+			t.Errorf("c.BuildDoc(): unexpected error\nwant: %s\ngot:  %s", want, got)
+		}
+	})
+}
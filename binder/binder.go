@@ -0,0 +1,334 @@
+// Package binder decodes the http requests into the input structs of the action functions, with the same tags as the doc (TagNameOption),
+// so the behavior at runtime and the doc do not disagree.
+package binder
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+// Binder binds the request to the input struct.
+type Binder struct {
+	*reflectopenapi.TagNameOption
+
+	// PathValue returns the value of the path parameter (e.g. chi.URLParam), default is (*http.Request).PathValue() (go1.22+).
+	PathValue func(r *http.Request, name string) string
+
+	MaxMemory int64 // the max memory of (*http.Request).ParseMultipartForm(), default is 32MB
+
+	fieldsCache sync.Map // reflect.Type -> []field
+}
+
+func New(tagNameOption *reflectopenapi.TagNameOption) *Binder {
+	if tagNameOption == nil {
+		tagNameOption = reflectopenapi.DefaultTagNameOption()
+	}
+	return &Binder{TagNameOption: tagNameOption, PathValue: defaultPathValue, MaxMemory: 32 << 20}
+}
+
+// Error is the error of binding, for the status code 400.
+type Error struct {
+	In   string // path, query, header, cookie, form or body
+	Name string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	if e.In == "body" {
+		return fmt.Sprintf("invalid request body: %v", e.Err)
+	}
+	return fmt.Sprintf("invalid %s parameter %q: %v", e.In, e.Name, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Bind decodes the request into dst (the pointer of the input struct).
+// The fields that are not found in the request keep the current values (e.g. the value passed to RegisterFuncAction.DefaultInput()),
+// and if the value is zero, the default value of the openapi-override tag is used (e.g. `openapi-override:"{'default': 20}"`).
+//
+// The order is: the body (json or form), and then the parameters (path, query, header, cookie). The body never sets the fields of the parameters.
+func (b *Binder) Bind(r *http.Request, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binder: dst must be a non-nil pointer of struct, but got %T", dst)
+	}
+	rv = rv.Elem()
+	fields := b.fields(rv.Type())
+
+	// body
+	hasBody, hasForm := false, false
+	for _, f := range fields {
+		switch f.in {
+		case "body":
+			hasBody = true
+		case "form":
+			hasForm = true
+		}
+	}
+	if hasForm {
+		if err := b.parseForm(r); err != nil {
+			return &Error{In: "body", Err: err}
+		}
+	} else if hasBody && r.Body != nil && r.Body != http.NoBody {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType == "" || mediaType == reflectopenapi.ContentTypeJSON || strings.HasSuffix(mediaType, "+json") {
+			// the body must not overwrite the parameters (e.g. {"limit": 9999} for `json:"limit" in:"query"`), so they are restored after decoding
+			saved := make([]reflect.Value, len(fields))
+			for i, f := range fields {
+				if f.in != "body" {
+					saved[i] = reflect.New(f.typ).Elem()
+					saved[i].Set(f.value(rv))
+				}
+			}
+			if err := json.NewDecoder(r.Body).Decode(dst); err != nil && err != io.EOF {
+				return &Error{In: "body", Err: err}
+			}
+			for i, f := range fields {
+				if saved[i].IsValid() {
+					f.value(rv).Set(saved[i])
+				}
+			}
+		}
+	}
+
+	// parameters
+	for _, f := range fields {
+		var values []string
+		switch f.in {
+		case "body":
+			continue
+		case "path":
+			if b.PathValue != nil {
+				if v := b.PathValue(r, f.name); v != "" {
+					values = []string{v}
+				}
+			}
+		case "query":
+			values = r.URL.Query()[f.name]
+		case "header":
+			values = r.Header.Values(f.name)
+		case "cookie":
+			if c, err := r.Cookie(f.name); err == nil {
+				values = []string{c.Value}
+			}
+		case "form":
+			if r.MultipartForm != nil && isFileField(f.typ) {
+				setFiles(f.value(rv), r.MultipartForm.File[f.name])
+				continue
+			}
+			values = r.PostForm[f.name]
+		}
+
+		fv := f.value(rv)
+		if len(values) == 0 {
+			if f.defaultValue != nil && fv.IsZero() {
+				if err := json.Unmarshal(f.defaultValue, fv.Addr().Interface()); err != nil {
+					return &Error{In: f.in, Name: f.name, Err: fmt.Errorf("default value: %w", err)}
+				}
+			}
+			continue
+		}
+		if err := setValues(fv, values); err != nil {
+			return &Error{In: f.in, Name: f.name, Err: err}
+		}
+	}
+	return nil
+}
+
+func (b *Binder) parseForm(r *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == reflectopenapi.ContentTypeMultipartForm {
+		return r.ParseMultipartForm(b.MaxMemory)
+	}
+	return r.ParseForm()
+}
+
+type field struct {
+	index        []int
+	in           string // "body" is the field of the json body
+	name         string // the name of the parameter
+	key          string // the name of the json field
+	typ          reflect.Type
+	defaultValue json.RawMessage // from the openapi-override tag
+}
+
+// value returns the (addressable) field value, allocating the embedded pointers.
+func (f *field) value(rv reflect.Value) reflect.Value {
+	for i, x := range f.index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+func (b *Binder) fields(rt reflect.Type) []field {
+	if v, ok := b.fieldsCache.Load(rt); ok {
+		return v.([]field)
+	}
+	fields := b.collectFields(rt, nil, nil)
+	b.fieldsCache.Store(rt, fields)
+	return fields
+}
+
+// collectFields collects the fields of the struct, the embedded structs are flattened (same as the doc).
+func (b *Binder) collectFields(rt reflect.Type, index []int, dst []field) []field {
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		idx := append(append([]int(nil), index...), i)
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct {
+			dst = b.collectFields(ft, idx, dst)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if v, ok := sf.Tag.Lookup(b.TagNameOption.NameTag); ok {
+			if left, _, _ := strings.Cut(v, ","); left != "" {
+				name = left
+			}
+			if name == "-" {
+				continue
+			}
+		}
+
+		key := name
+		in := "body"
+		if v, ok := sf.Tag.Lookup(b.TagNameOption.ParamTypeTag); ok {
+			switch v := strings.ToLower(v); v {
+			case "path", "query", "header", "cookie":
+				in = v
+				if v, ok := sf.Tag.Lookup(in); ok { // e.g. `in:"header" header:"X-Request-ID"`
+					name = v
+				}
+			case "form", "formdata":
+				in = "form"
+				if v, ok := sf.Tag.Lookup("form"); ok {
+					name, _, _ = strings.Cut(v, ",")
+				}
+			}
+		}
+
+		f := field{index: idx, in: in, name: name, key: key, typ: sf.Type}
+		if v, ok := sf.Tag.Lookup(b.TagNameOption.OverrideTag); ok && in != "body" {
+			var override struct {
+				Default json.RawMessage `json:"default"`
+			}
+			s := strings.ReplaceAll(strings.ReplaceAll(v, `\`, `\\`), "'", "\"")
+			if err := json.Unmarshal([]byte(s), &override); err == nil && len(override.Default) > 0 {
+				f.defaultValue = override.Default
+			}
+		}
+		dst = append(dst, f)
+	}
+	return dst
+}
+
+var (
+	rtextUnmarshalerType = reflect.TypeOf(func(encoding.TextUnmarshaler) {}).In(0)
+	rfileHeaderType      = reflect.TypeOf(&multipart.FileHeader{})
+)
+
+// setValues sets the string values to the field, converting to the type of the field (slices are for the repeated values).
+func setValues(rv reflect.Value, values []string) error {
+	if rv.Kind() == reflect.Slice && !reflect.PointerTo(rv.Type()).Implements(rtextUnmarshalerType) && rv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(rv.Type(), len(values), len(values))
+		for i, v := range values {
+			if err := setValue(slice.Index(i), v); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	}
+	return setValue(rv, values[0])
+}
+
+func setValue(rv reflect.Value, value string) error {
+	if rv.Kind() == reflect.Pointer {
+		v := reflect.New(rv.Type().Elem())
+		if err := setValue(v.Elem(), value); err != nil {
+			return err
+		}
+		rv.Set(v)
+		return nil
+	}
+	if reflect.PointerTo(rv.Type()).Implements(rtextUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(v)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 { // []byte
+			rv.SetBytes([]byte(value))
+			return nil
+		}
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	default:
+		return fmt.Errorf("unsupported type %v", rv.Type())
+	}
+	return nil
+}
+
+func isFileField(rt reflect.Type) bool {
+	return rt == rfileHeaderType || (rt.Kind() == reflect.Slice && rt.Elem() == rfileHeaderType)
+}
+
+func setFiles(rv reflect.Value, files []*multipart.FileHeader) {
+	if len(files) == 0 {
+		return
+	}
+	if rv.Kind() == reflect.Slice {
+		rv.Set(reflect.ValueOf(files))
+		return
+	}
+	rv.Set(reflect.ValueOf(files[0]))
+}
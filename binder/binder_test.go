@@ -0,0 +1,233 @@
+package binder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+type Pagination struct {
+	Limit  int     `json:"limit" in:"query" openapi-override:"{'default': 20}"`
+	Cursor *string `json:"cursor,omitempty" in:"query"`
+}
+
+type UpdateUserInput struct {
+	UserID    int64    `json:"userId" in:"path"`
+	Tags      []string `json:"tag" in:"query"`
+	Verbose   bool     `json:"verbose" in:"query"`
+	RequestID string   `json:"requestId" in:"header" header:"X-Request-ID"`
+	Session   string   `json:"session" in:"cookie"`
+	Pagination
+
+	Name string `json:"name"`
+	Age  int    `json:"age,omitempty"`
+}
+
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age,omitempty"`
+}
+
+type UpdateUserOutput struct {
+	User
+	Location string `json:"location" in:"header" header:"Location"`
+}
+
+// RenameUserOutput has the fields that are not in alphabetical order.
+type RenameUserOutput struct {
+	Name     string `json:"name"`
+	ID       int64  `json:"id"`
+	Nickname string `json:"nickname,omitempty"`
+	Location string `json:"location" in:"header" header:"Location"`
+}
+
+var ErrNotFound = errors.New("not found")
+
+func UpdateUser(ctx context.Context, input UpdateUserInput) (*UpdateUserOutput, error) {
+	if input.UserID == 0 {
+		return nil, ErrNotFound
+	}
+	return &UpdateUserOutput{User: User{ID: input.UserID, Name: input.Name, Age: input.Age}, Location: "/users/1"}, nil
+}
+
+func CreateUser(ctx context.Context, input UpdateUserInput) (reflectopenapi.Created[User], error) {
+	return reflectopenapi.Created[User]{Body: User{ID: 1, Name: input.Name}}, nil
+}
+
+func RenameUser(ctx context.Context, input UpdateUserInput) (RenameUserOutput, error) {
+	return RenameUserOutput{Name: input.Name, ID: input.UserID, Location: "/users/1"}, nil
+}
+
+func newBinder() *Binder {
+	b := New(nil)
+	b.PathValue = func(r *http.Request, name string) string {
+		if name == "userId" {
+			return strings.TrimPrefix(r.URL.Path, "/users/")
+		}
+		return ""
+	}
+	return b
+}
+
+func TestBind(t *testing.T) {
+	b := newBinder()
+
+	t.Run("ok", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/users/10?tag=a&tag=b&verbose=true", strings.NewReader(`{"name": "foo"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "xxx")
+		req.AddCookie(&http.Cookie{Name: "session", Value: "yyy"})
+
+		got := UpdateUserInput{Age: 20} // default input
+		if err := b.Bind(req, &got); err != nil {
+			t.Fatalf("Bind(): unexpected error: %+v", err)
+		}
+
+		want := UpdateUserInput{
+			UserID: 10, Tags: []string{"a", "b"}, Verbose: true, RequestID: "xxx", Session: "yyy",
+			Pagination: Pagination{Limit: 20}, // from openapi-override
+			Name:       "foo", Age: 20,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Bind() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/users/10?limit=5&cursor=c", nil)
+
+		var got UpdateUserInput
+		if err := b.Bind(req, &got); err != nil {
+			t.Fatalf("Bind(): unexpected error: %+v", err)
+		}
+		cursor := "c"
+		if diff := cmp.Diff(Pagination{Limit: 5, Cursor: &cursor}, got.Pagination); diff != "" {
+			t.Errorf("Bind() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("body does not overwrite parameters", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/users/10", strings.NewReader(`{"name": "foo", "limit": 9999, "userId": 1, "requestId": "zzz"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		var got UpdateUserInput
+		if err := b.Bind(req, &got); err != nil {
+			t.Fatalf("Bind(): unexpected error: %+v", err)
+		}
+		want := UpdateUserInput{
+			UserID:     10,
+			Pagination: Pagination{Limit: 20}, // from openapi-override, not from the body
+			Name:       "foo",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Bind() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/users/foo", nil)
+
+		var got UpdateUserInput
+		err := b.Bind(req, &got)
+		var bindErr *Error
+		if !errors.As(err, &bindErr) {
+			t.Fatalf("Bind(): must be *Error, but got %#+v", err)
+		}
+		if want, got := "path", bindErr.In; want != got {
+			t.Errorf("Error.In, want %q, but got %q", want, got)
+		}
+		if want, got := "userId", bindErr.Name; want != got {
+			t.Errorf("Error.Name, want %q, but got %q", want, got)
+		}
+	})
+
+	t.Run("same as doc", func(t *testing.T) {
+		c := &reflectopenapi.Config{SkipValidation: true}
+		doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			m.Put("/users/{userId}", UpdateUser)
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		var want []string
+		for _, p := range doc.Paths["/users/{userId}"].Put.Parameters {
+			want = append(want, p.Value.In+":"+p.Value.Name)
+		}
+		var got []string
+		for _, f := range b.fields(reflect.TypeOf(UpdateUserInput{})) {
+			if f.in != "body" {
+				got = append(got, f.in+":"+f.name)
+			}
+		}
+		sort.Strings(want)
+		sort.Strings(got)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("parameters mismatch (-doc +binder):\n%s", diff)
+		}
+	})
+}
+
+func TestHandler(t *testing.T) {
+	b := newBinder()
+	catalog := reflectopenapi.NewErrorCatalog()
+	catalog.Register(ErrNotFound, 404, "user is not found")
+
+	update := NewHandler(b, UpdateUser)
+	update.ErrorCatalog = catalog
+	create := NewHandler(b, CreateUser)
+	rename := NewHandler(b, RenameUser)
+
+	cases := []struct {
+		msg     string
+		handler http.Handler
+		path    string
+		body    string
+
+		code   int
+		want   string
+		header http.Header
+	}{
+		{msg: "ok", handler: update, path: "/users/1", body: `{"name": "foo", "location": "ignored"}`,
+			code: 200, want: `{"id":1,"name":"foo"}`, header: http.Header{"Location": {"/users/1"}}},
+		{msg: "order of properties", handler: rename, path: "/users/1", body: `{"name": "foo"}`,
+			code: 200, want: `{"name":"foo","id":1}`, header: http.Header{"Location": {"/users/1"}}},
+		{msg: "status code", handler: create, path: "/users/1", body: `{"name": "foo"}`,
+			code: 201, want: `{"id":1,"name":"foo"}`},
+		{msg: "bind error", handler: update, path: "/users/foo",
+			code: 400, want: `{"code":400,"message":"invalid path parameter \"userId\": strconv.ParseInt: parsing \"foo\": invalid syntax"}`},
+		{msg: "error catalog", handler: update, path: "/users/0",
+			code: 404, want: `{"code":404,"message":"not found"}`},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", c.path, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c.handler.ServeHTTP(rec, req)
+
+			if want, got := c.code, rec.Code; want != got {
+				t.Errorf("status code, want %d, but got %d", want, got)
+			}
+			if diff := cmp.Diff(c.want+"\n", rec.Body.String()); diff != "" {
+				t.Errorf("response mismatch (-want +got):\n%s", diff)
+			}
+			for k := range c.header {
+				if want, got := c.header.Get(k), rec.Header().Get(k); want != got {
+					t.Errorf("header %q, want %q, but got %q", k, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package binder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+// Handler is the http.Handler of the action function (func(context.Context, I) (O, error)).
+//
+// The input is decoded by Binder, and the output is encoded as JSON with the status code of the output type
+// (reflectopenapi.StatusCoder or the marker field with the status tag, default is 200), the fields with `in:"header"` are sent as the response headers.
+type Handler[I any, O any] struct {
+	Binder  *Binder
+	Action  func(context.Context, I) (O, error)
+	Default I // the default input, the same value as RegisterFuncAction.DefaultInput()
+
+	ErrorCatalog *reflectopenapi.ErrorCatalog // if not nil, the status code of the error is looked up (default is 500), and binding errors are 400
+	// ErrorResponse builds the response body of the error, the returned value is encoded as JSON.
+	// e.g. func(code int, err error) interface{} { return Error{Code: int32(code), Message: err.Error()} }  // the type used as Config.DefaultError
+	ErrorResponse func(code int, err error) interface{}
+}
+
+func NewHandler[I any, O any](b *Binder, action func(context.Context, I) (O, error)) *Handler[I, O] {
+	return &Handler[I, O]{Binder: b, Action: action}
+}
+
+// WithDefault sets the default input.
+func (h *Handler[I, O]) WithDefault(input I) *Handler[I, O] {
+	h.Default = input
+	return h
+}
+
+func (h *Handler[I, O]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	input := h.Default
+	if err := h.Binder.Bind(r, &input); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	output, err := h.Action(r.Context(), input)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	rv := reflect.ValueOf(output)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	body := interface{}(output)
+	if rv.Kind() == reflect.Struct {
+		if headers := h.Binder.responseHeaders(rv); len(headers) > 0 {
+			for k, v := range headers {
+				w.Header().Set(k, v)
+			}
+			body = h.Binder.withoutHeaders(output, rv)
+		}
+	}
+
+	code := h.Binder.statusCode(output, rv)
+	if code == http.StatusNoContent {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", reflectopenapi.ContentTypeJSON)
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[WARN]  binder: encode response of %s %s: %+v", r.Method, r.URL.Path, err)
+	}
+}
+
+func (h *Handler[I, O]) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusInternalServerError
+	var bindErr *Error
+	if errors.As(err, &bindErr) {
+		code = http.StatusBadRequest
+	} else if h.ErrorCatalog != nil {
+		if def, ok := h.ErrorCatalog.Lookup(err); ok {
+			code = def.Code
+		}
+	}
+
+	var body interface{}
+	if h.ErrorResponse != nil {
+		body = h.ErrorResponse(code, err)
+	} else {
+		body = map[string]interface{}{"code": code, "message": err.Error()}
+	}
+
+	w.Header().Set("Content-Type", reflectopenapi.ContentTypeJSON)
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[WARN]  binder: encode error response of %s %s: %+v", r.Method, r.URL.Path, err)
+	}
+}
+
+// statusCode returns the status code of the output, same as the doc.
+func (b *Binder) statusCode(output interface{}, rv reflect.Value) int {
+	if v, ok := output.(reflectopenapi.StatusCoder); ok {
+		return v.StatusCode()
+	}
+	if rv.IsValid() && rv.CanAddr() {
+		if v, ok := rv.Addr().Interface().(reflectopenapi.StatusCoder); ok {
+			return v.StatusCode()
+		}
+	}
+	if rv.Kind() == reflect.Struct && b.TagNameOption.StatusTag != "" {
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			if v, ok := rt.Field(i).Tag.Lookup(b.TagNameOption.StatusTag); ok {
+				if code, err := strconv.Atoi(v); err == nil {
+					return code
+				}
+			}
+		}
+	}
+	return http.StatusOK
+}
+
+// responseHeaders returns the values of the fields with `in:"header"`.
+func (b *Binder) responseHeaders(rv reflect.Value) map[string]string {
+	var headers map[string]string
+	for _, f := range b.fields(rv.Type()) {
+		if f.in != "header" {
+			continue
+		}
+		fv := f.value(rv)
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Pointer || fv.IsZero() {
+			continue
+		}
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[f.name] = formatValue(fv)
+	}
+	return headers
+}
+
+// withoutHeaders returns the body without the header fields (the header fields are not included in the schema of the response body).
+// The order of the properties is the same as the fields of the output.
+func (b *Binder) withoutHeaders(output interface{}, rv reflect.Value) interface{} {
+	if _, ok := output.(json.Marshaler); ok {
+		return b.withoutHeadersMap(output, rv)
+	}
+
+	rt := rv.Type()
+	var structFields []reflect.StructField
+	var values []reflect.Value
+	for _, f := range b.fields(rt) {
+		if f.in == "header" {
+			continue
+		}
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil {
+			continue // the embedded pointer is nil
+		}
+		if !fv.CanInterface() {
+			return b.withoutHeadersMap(output, rv) // e.g. the field of the unexported embedded struct
+		}
+		sf := rt.FieldByIndex(f.index)
+		tag := f.key
+		if _, opts, ok := strings.Cut(sf.Tag.Get(b.TagNameOption.NameTag), ","); ok {
+			tag += "," + opts
+		}
+		structFields = append(structFields, reflect.StructField{
+			Name: fmt.Sprintf("F%d", len(structFields)),
+			Type: sf.Type,
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:%q`, tag)),
+		})
+		values = append(values, fv)
+	}
+
+	body := reflect.New(reflect.StructOf(structFields)).Elem()
+	for i, v := range values {
+		body.Field(i).Set(v)
+	}
+	return body.Interface()
+}
+
+// withoutHeadersMap is withoutHeaders for the output with its own MarshalJSON, the order of the properties is not kept.
+func (b *Binder) withoutHeadersMap(output interface{}, rv reflect.Value) interface{} {
+	data, err := json.Marshal(output)
+	if err != nil {
+		return output
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return output // e.g. Created[T]
+	}
+	for _, f := range b.fields(rv.Type()) {
+		if f.in != "header" {
+			continue
+		}
+		delete(body, f.key)
+	}
+	return body
+}
+
+func formatValue(rv reflect.Value) string {
+	if v, ok := rv.Interface().(interface{ MarshalText() ([]byte, error) }); ok {
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	default:
+		s, _ := json.Marshal(rv.Interface())
+		return strings.Trim(string(s), `"`)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !go1.22
+
+package binder
+
+import "net/http"
+
+// defaultPathValue returns empty, (*http.Request).PathValue() is not available before go1.22.
+func defaultPathValue(r *http.Request, name string) string {
+	return ""
+}
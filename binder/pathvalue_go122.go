@@ -0,0 +1,9 @@
+//go:build go1.22
+
+package binder
+
+import "net/http"
+
+func defaultPathValue(r *http.Request, name string) string {
+	return r.PathValue(name)
+}
@@ -0,0 +1,495 @@
+// Package clientgen generates the go client package from the doc (built by reflectopenapi.Config.BuildDoc()).
+//
+// The generated package has one method per operation (named from the operationId), the request structs with the parameters,
+// and the types of the components/schemas (or the original go types, if they are importable).
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
+	"github.com/podhmo/reflect-openapi/walknode"
+)
+
+type Config struct {
+	PackageName string // the package name of the generated code, default is "client"
+	PackagePath string // the import path of the generated package, for not importing itself
+
+	DisableReuse bool                      // if true, the original go types are not reused (always generating the types)
+	IsImportable func(pkgpath string) bool // whether the package of the original go type can be imported, default is DefaultIsImportable
+}
+
+// DefaultIsImportable returns false for the main package and the test packages.
+func DefaultIsImportable(pkgpath string) bool {
+	return pkgpath != "" && pkgpath != "main" && !strings.HasSuffix(pkgpath, "_test")
+}
+
+// Generate writes the go client package.
+func Generate(w io.Writer, doc *openapi3.T, info *info.Info, config *Config) error {
+	if config == nil {
+		config = &Config{}
+	}
+	g := &generator{
+		Config:  config,
+		doc:     doc,
+		info:    info,
+		imports: map[string]string{},
+		aliases: map[string]bool{},
+	}
+	if g.PackageName == "" {
+		g.PackageName = "client"
+	}
+	if g.IsImportable == nil {
+		g.IsImportable = DefaultIsImportable
+	}
+	for _, alias := range stdImports {
+		g.aliases[alias] = true
+	}
+
+	g.resolveNames()
+	body := new(bytes.Buffer)
+	if err := g.writeOperations(body); err != nil {
+		return err
+	}
+	if err := g.writeComponents(body); err != nil {
+		return err
+	}
+
+	out := new(bytes.Buffer)
+	fmt.Fprintln(out, "// Code generated by github.com/podhmo/reflect-openapi/clientgen; DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "package %s\n\n", g.PackageName)
+	fmt.Fprintln(out, "import (")
+	for _, path := range []string{"bytes", "context", "encoding", "encoding/json", "fmt", "io", "net/http", "net/url", "strings"} {
+		fmt.Fprintf(out, "\t%q\n", path)
+	}
+	if g.useTime {
+		fmt.Fprintf(out, "\t%q\n", "time")
+	}
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	if len(paths) > 0 {
+		fmt.Fprintln(out)
+		for _, path := range paths {
+			if alias := g.imports[path]; alias != path[strings.LastIndex(path, "/")+1:] {
+				fmt.Fprintf(out, "\t%s %q\n", alias, path)
+			} else {
+				fmt.Fprintf(out, "\t%q\n", path)
+			}
+		}
+	}
+	fmt.Fprintln(out, ")")
+	fmt.Fprintln(out)
+	io.WriteString(out, runtimeCode)
+	out.Write(body.Bytes())
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("format generated code: %w", err)
+	}
+	if _, err := w.Write(code); err != nil {
+		return fmt.Errorf("write generated code: %w", err)
+	}
+	return nil
+}
+
+type generator struct {
+	*Config
+	doc  *openapi3.T
+	info *info.Info
+
+	imports        map[string]string // path -> alias
+	aliases        map[string]bool
+	names          map[string]bool   // the names of the declared types
+	componentNames map[string]string // the name in components/schemas -> the name of the generated type
+	useTime        bool
+}
+
+type operation struct {
+	Name   string
+	Method string
+	Path   string
+	Op     *openapi3.Operation
+}
+
+func (g *generator) operations() []operation {
+	var ops []operation
+	seen := map[string]int{}
+	walknode.PathItem(g.doc, func(pathItem *openapi3.PathItem, path string) {
+		walknode.Operation(pathItem, func(op *openapi3.Operation, method string) {
+			name := op.OperationID
+			if i := strings.LastIndexAny(name, "./"); i >= 0 {
+				name = name[i+1:]
+			}
+			name = goName(name)
+			if name == "" {
+				name = goName(strings.ToLower(method) + " " + path)
+			}
+			seen[name]++
+			if n := seen[name]; n > 1 {
+				name = fmt.Sprintf("%s%d", name, n)
+			}
+			ops = append(ops, operation{Name: name, Method: method, Path: path, Op: op})
+		})
+	})
+	return ops
+}
+
+func (g *generator) writeOperations(w *bytes.Buffer) error {
+	for _, op := range g.operations() {
+		if err := g.writeOperation(w, op); err != nil {
+			return fmt.Errorf("operation %s %s (%s): %w", op.Method, op.Path, op.Op.OperationID, err)
+		}
+	}
+	return nil
+}
+
+type param struct {
+	Field    string
+	Name     string
+	In       string
+	TypeExpr string
+	Optional bool // the field is the pointer (or the slice)
+	Slice    bool
+}
+
+func (g *generator) writeOperation(w *bytes.Buffer, op operation) error {
+	// input
+	inputName := oasutil.UniqueName(g.names, op.Name+"Request")
+	var params []param
+	fields := map[string]bool{}
+	for _, ref := range op.Op.Parameters {
+		p := ref.Value
+		if p == nil || p.Schema == nil {
+			continue
+		}
+		if p.In == openapi3.ParameterInCookie || p.In == openapi3.ParameterInHeader || p.In == openapi3.ParameterInPath || p.In == openapi3.ParameterInQuery {
+			field := oasutil.UniqueName(fields, goName(p.Name))
+			typ := g.typeExpr(p.Schema)
+			slice := strings.HasPrefix(typ, "[]")
+			optional := !p.Required && !slice
+			params = append(params, param{Field: field, Name: p.Name, In: p.In, TypeExpr: typ, Optional: optional, Slice: slice})
+		}
+	}
+
+	var bodyType, bodyContentType string
+	if body := op.Op.RequestBody; body != nil && body.Value != nil {
+		contentType, media := oasutil.RequestBodyMedia(body.Value)
+		if media != nil {
+			bodyContentType = contentType
+			if contentType == "application/json" && media.Schema != nil {
+				bodyType = g.typeExpr(media.Schema)
+			} else {
+				bodyType = "io.Reader"
+			}
+		}
+	}
+
+	hasInput := len(params) > 0 || bodyType != ""
+	if hasInput {
+		fmt.Fprintf(w, "// %s is the request of %s.\n", inputName, op.Name)
+		fmt.Fprintf(w, "type %s struct {\n", inputName)
+		for _, p := range params {
+			if ref := op.Op.Parameters[paramIndex(op.Op.Parameters, p)]; ref.Value.Description != "" {
+				writeComment(w, "\t", ref.Value.Description)
+			}
+			typ := p.TypeExpr
+			if p.Optional {
+				typ = "*" + typ
+			}
+			fmt.Fprintf(w, "\t%s %s // in:%s %q\n", p.Field, typ, p.In, p.Name)
+		}
+		if bodyType != "" {
+			if len(params) > 0 {
+				w.WriteRune('\n')
+			}
+			if description := op.Op.RequestBody.Value.Description; description != "" {
+				writeComment(w, "\t", description)
+			}
+			fmt.Fprintf(w, "\tBody %s\n", bodyType)
+			if bodyContentType != "application/json" {
+				fmt.Fprintf(w, "\tContentType string // default is %q\n", bodyContentType)
+			}
+		}
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+
+	// output
+	successCode, success := oasutil.SuccessResponse(op.Op)
+	var outputType string
+	var outputIsJSON bool
+	if success != nil {
+		if media := success.Content.Get("application/json"); media != nil && media.Schema != nil {
+			outputType = g.typeExpr(media.Schema)
+			outputIsJSON = true
+			if g.isStruct(media.Schema) {
+				outputType = "*" + outputType
+			}
+		} else if len(success.Content) > 0 {
+			outputType = "[]byte"
+		}
+	}
+
+	// method
+	summary := op.Op.Summary
+	if summary == "" {
+		summary = op.Op.Description
+	}
+	if summary != "" {
+		writeComment(w, "", op.Name+" "+strings.TrimPrefix(summary, op.Name+" "))
+		fmt.Fprintln(w, "//")
+		fmt.Fprintf(w, "// %s %s\n", op.Method, op.Path)
+	} else {
+		fmt.Fprintf(w, "// %s calls %s %s.\n", op.Name, op.Method, op.Path)
+	}
+	if op.Op.Deprecated {
+		fmt.Fprintln(w, "//")
+		fmt.Fprintln(w, "// Deprecated: this operation is deprecated.")
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(ctx context.Context", op.Name)
+	if hasInput {
+		fmt.Fprintf(w, ", input %s", inputName)
+	}
+	returnZero := "err"
+	if outputType != "" {
+		fmt.Fprintf(w, ") (%s, error) {\n", outputType)
+		returnZero = "nil, err"
+		if !strings.HasPrefix(outputType, "*") && !strings.HasPrefix(outputType, "[]") && !strings.HasPrefix(outputType, "map[") && outputType != "interface{}" {
+			fmt.Fprintf(w, "\tvar zero %s\n", outputType)
+			returnZero = "zero, err"
+		}
+	} else {
+		fmt.Fprintln(w, ") error {")
+	}
+
+	// path
+	fmt.Fprintf(w, "\tpath := %s\n", g.pathExpr(op.Path, params))
+	fmt.Fprintln(w, "\tquery := url.Values{}")
+	for _, p := range params {
+		if p.In != openapi3.ParameterInQuery {
+			continue
+		}
+		switch {
+		case p.Slice:
+			fmt.Fprintf(w, "\tfor _, v := range input.%s {\n\t\tquery.Add(%q, toString(v))\n\t}\n", p.Field, p.Name)
+		case p.Optional:
+			fmt.Fprintf(w, "\tif input.%s != nil {\n\t\tquery.Set(%q, toString(*input.%s))\n\t}\n", p.Field, p.Name, p.Field)
+		default:
+			fmt.Fprintf(w, "\tquery.Set(%q, toString(input.%s))\n", p.Name, p.Field)
+		}
+	}
+
+	// body
+	switch {
+	case bodyType == "":
+		fmt.Fprintln(w, "\tvar body io.Reader")
+	case bodyContentType == "application/json":
+		fmt.Fprintln(w, "\tbody, err := jsonBody(input.Body)")
+		fmt.Fprintf(w, "\tif err != nil {\n\t\treturn %s\n\t}\n", returnZero)
+	default:
+		fmt.Fprintln(w, "\tbody := input.Body")
+	}
+
+	fmt.Fprintf(w, "\treq, err := c.newRequest(ctx, %q, path, query, body)\n", op.Method)
+	fmt.Fprintf(w, "\tif err != nil {\n\t\treturn %s\n\t}\n", returnZero)
+	switch {
+	case bodyType == "":
+	case bodyContentType == "application/json":
+		fmt.Fprintln(w, "\treq.Header.Set(\"Content-Type\", \"application/json\")")
+	default:
+		fmt.Fprintf(w, "\tif input.ContentType != \"\" {\n\t\treq.Header.Set(\"Content-Type\", input.ContentType)\n\t} else {\n\t\treq.Header.Set(\"Content-Type\", %q)\n\t}\n", bodyContentType)
+	}
+	for _, p := range params {
+		var set string
+		switch p.In {
+		case openapi3.ParameterInHeader:
+			set = fmt.Sprintf("req.Header.Add(%q, toString(%%s))", http.CanonicalHeaderKey(p.Name))
+		case openapi3.ParameterInCookie:
+			set = fmt.Sprintf("req.AddCookie(&http.Cookie{Name: %q, Value: toString(%%s)})", p.Name)
+		default:
+			continue
+		}
+		switch {
+		case p.Slice:
+			fmt.Fprintf(w, "\tfor _, v := range input.%s {\n\t\t%s\n\t}\n", p.Field, fmt.Sprintf(set, "v"))
+		case p.Optional:
+			fmt.Fprintf(w, "\tif input.%s != nil {\n\t\t%s\n\t}\n", p.Field, fmt.Sprintf(set, "*input."+p.Field))
+		default:
+			fmt.Fprintf(w, "\t%s\n", fmt.Sprintf(set, "input."+p.Field))
+		}
+	}
+
+	// response
+	fmt.Fprintln(w, "\tres, err := c.do(req)")
+	fmt.Fprintf(w, "\tif err != nil {\n\t\treturn %s\n\t}\n", returnZero)
+	fmt.Fprintln(w, "\tdefer res.Body.Close()")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tswitch res.StatusCode {")
+	if success != nil {
+		fmt.Fprintf(w, "\tcase %d:\n", successCode)
+		switch {
+		case outputType == "":
+			fmt.Fprintln(w, "\t\treturn nil")
+		case outputIsJSON && strings.HasPrefix(outputType, "*"):
+			fmt.Fprintf(w, "\t\tvar out %s\n", strings.TrimPrefix(outputType, "*"))
+			fmt.Fprintf(w, "\t\tif err := decodeJSON(res, &out); err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+			fmt.Fprintln(w, "\t\treturn &out, nil")
+		case outputIsJSON:
+			fmt.Fprintf(w, "\t\tvar out %s\n", outputType)
+			fmt.Fprintf(w, "\t\tif err := decodeJSON(res, &out); err != nil {\n\t\t\treturn %s\n\t\t}\n", returnZero)
+			fmt.Fprintln(w, "\t\treturn out, nil")
+		default:
+			fmt.Fprintln(w, "\t\treturn io.ReadAll(res.Body)")
+		}
+	}
+	var defaultError *openapi3.Response
+	walknode.Response(op.Op, func(ref *openapi3.ResponseRef, name string) {
+		if ref.Value == nil {
+			return
+		}
+		if name == "default" {
+			defaultError = ref.Value
+			return
+		}
+		code, err := strconv.Atoi(name)
+		if err != nil || code < 300 {
+			return
+		}
+		if typ := g.errorType(ref.Value); typ != "" {
+			fmt.Fprintf(w, "\tcase %d:\n", code)
+			fmt.Fprintf(w, "\t\treturn %sdecodeError(res, &%s{})\n", strings.TrimSuffix(returnZero, "err"), typ)
+		}
+	})
+	fmt.Fprintln(w, "\tdefault:")
+	if typ := g.errorType(defaultError); typ != "" {
+		fmt.Fprintf(w, "\t\treturn %sdecodeError(res, &%s{})\n", strings.TrimSuffix(returnZero, "err"), typ)
+	} else {
+		fmt.Fprintf(w, "\t\treturn %sdecodeError(res, nil)\n", strings.TrimSuffix(returnZero, "err"))
+	}
+	fmt.Fprintln(w, "\t}")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	return nil
+}
+
+// errorType returns the type of the body of the error response, if it is json.
+func (g *generator) errorType(res *openapi3.Response) string {
+	if res == nil {
+		return ""
+	}
+	media := res.Content.Get("application/json")
+	if media == nil || media.Schema == nil {
+		return ""
+	}
+	return g.typeExpr(media.Schema)
+}
+
+// pathExpr returns the expression of the path (e.g. "/pets/" + url.PathEscape(toString(input.ID))).
+func (g *generator) pathExpr(path string, params []param) string {
+	var parts []string
+	rest := path
+	for {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			break
+		}
+		name := rest[start+1 : end]
+		field := ""
+		for _, p := range params {
+			if p.In == openapi3.ParameterInPath && p.Name == name {
+				field = "input." + p.Field
+				if p.Optional {
+					field = "*" + field
+				}
+				break
+			}
+		}
+		if field == "" { // the path parameter is not declared
+			break
+		}
+		if rest[:start] != "" {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		parts = append(parts, fmt.Sprintf("url.PathEscape(toString(%s))", field))
+		rest = rest[end+1:]
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func paramIndex(params openapi3.Parameters, p param) int {
+	for i, ref := range params {
+		if ref.Value != nil && ref.Value.Name == p.Name && ref.Value.In == p.In {
+			return i
+		}
+	}
+	return -1
+}
+
+func writeComment(w *bytes.Buffer, indent string, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fmt.Fprintf(w, "%s// %s\n", indent, strings.TrimRight(line, " \t"))
+	}
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "uri": "URI", "http": "HTTP", "api": "API", "json": "JSON", "uuid": "UUID", "ip": "IP"}
+
+// goName returns the exported go identifier (e.g. "user_id" -> "UserID", "X-Request-ID" -> "XRequestID").
+func goName(s string) string {
+	var parts []string
+	start := -1
+	for i, r := range s + " " {
+		isAlnum := ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+		if isAlnum && start < 0 {
+			start = i
+		} else if !isAlnum && start >= 0 {
+			parts = append(parts, s[start:i])
+			start = -1
+		}
+	}
+
+	w := new(strings.Builder)
+	for _, part := range parts {
+		for _, word := range splitCamel(part) {
+			if v, ok := initialisms[strings.ToLower(word)]; ok {
+				w.WriteString(v)
+				continue
+			}
+			w.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	name := w.String()
+	if name != "" && '0' <= name[0] && name[0] <= '9' {
+		name = "X" + name
+	}
+	return name
+}
+
+// splitCamel splits the camel case word (e.g. "userId" -> ["user", "Id"]).
+func splitCamel(s string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if 'A' <= s[i] && s[i] <= 'Z' && 'a' <= s[i-1] && s[i-1] <= 'z' {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	return append(words, s[start:])
+}
@@ -0,0 +1,143 @@
+package clientgen_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/clientgen"
+	"github.com/podhmo/reflect-openapi/info"
+)
+
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Pet is the pet
+type Pet struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"` // name of pet
+	Tag       string    `json:"tag,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	Owner     *Owner    `json:"owner,omitempty"`
+	Tags      []string  `json:"tags"`
+}
+
+type Owner struct {
+	Name string `json:"name"`
+}
+
+type ListPetsInput struct {
+	Tags      []string `json:"tags" in:"query"`
+	Limit     int32    `json:"limit,omitempty" in:"query"`
+	RequestID string   `json:"requestId" in:"header" header:"X-Request-ID"`
+}
+
+// ListPets returns all pets
+func ListPets(ctx context.Context, input ListPetsInput) ([]Pet, error) { return nil, nil }
+
+type FindPetInput struct {
+	ID int64 `json:"id" in:"path"`
+}
+
+// FindPet returns a pet
+func FindPet(ctx context.Context, input FindPetInput) (*Pet, error) { return nil, nil }
+
+type AddPetInput struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func AddPet(ctx context.Context, input AddPetInput) (reflectopenapi.Created[Pet], error) {
+	return reflectopenapi.Created[Pet]{}, nil
+}
+
+type DeletePetInput struct {
+	ID int64 `json:"id" in:"path"`
+}
+
+type DeletePetOutput struct {
+	_ struct{} `status:"204"`
+}
+
+func DeletePet(ctx context.Context, input DeletePetInput) (DeletePetOutput, error) {
+	return DeletePetOutput{}, nil
+}
+
+func ListLinks(ctx context.Context) ([]info.Link, error) { return nil, nil }
+
+var ErrNotFound = fmt.Errorf("not found")
+
+func TestGenerate(t *testing.T) {
+	c := &reflectopenapi.Config{SkipValidation: true, Info: info.New(), DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterError(ErrNotFound, 404, "not found")
+		m.Get("/pets", ListPets)
+		m.Post("/pets", AddPet)
+		m.Get("/pets/{id}", FindPet).Errors(ErrNotFound)
+		m.Delete("/pets/{id}", DeletePet)
+		m.Get("/links", ListLinks) // info.Link is importable
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := clientgen.Generate(&buf, doc, c.Info, &clientgen.Config{PackageName: "petstore"}); err != nil {
+		t.Fatalf("clientgen.Generate(): unexpected error: %+v", err)
+	}
+	code := buf.String()
+
+	// the generated code must be compiled
+	t.Run("type-check", func(t *testing.T) {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "client.go", code, parser.AllErrors)
+		if err != nil {
+			t.Fatalf("parse: unexpected error: %+v\n%s", err, code)
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)} // the imported packages are resolved from the module of this test
+		if _, err := conf.Check("petstore", fset, []*ast.File{f}, nil); err != nil {
+			t.Errorf("type-check: unexpected error: %+v\n%s", err, code)
+		}
+	})
+
+	// the generated code is formatted, so the snippets are compared line by line
+	cases := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "package", want: "package petstore"},
+		{msg: "importable type is reused", want: "\t\"github.com/podhmo/reflect-openapi/info\""},
+		{msg: "method with request", want: "func (c *Client) ListPets(ctx context.Context, input ListPetsRequest) ([]Pet, error) {"},
+		{msg: "method without request", want: "func (c *Client) ListLinks(ctx context.Context) ([]info.Link, error) {"},
+		{msg: "status code of output", want: "func (c *Client) AddPet(ctx context.Context, input AddPetRequest) (*Pet, error) {"},
+		{msg: "no content", want: "func (c *Client) DeletePet(ctx context.Context, input DeletePetRequest) error {"},
+		{msg: "query param (repeated)", want: "\tTags       []string // in:query \"tags\""},
+		{msg: "query param (optional)", want: "\tLimit      *int32   // in:query \"limit\""},
+		{msg: "header param", want: "\tXRequestID *string  // in:header \"X-Request-ID\""},
+		{msg: "path param", want: "\tpath := \"/pets/\" + url.PathEscape(toString(input.ID))"},
+		{msg: "request body", want: "\tBody AddPetInput"},
+		{msg: "documented error", want: "\tcase 404:\n\t\treturn nil, decodeError(res, &Error{})"},
+		{msg: "default error", want: "\tdefault:\n\t\treturn decodeError(res, &Error{})"},
+		{msg: "time.Time", want: "\tCreatedAt time.Time `json:\"createdAt\"`"},
+		{msg: "optional struct field", want: "\tOwner     *Owner    `json:\"owner,omitempty\"`"},
+	}
+	for _, c := range cases {
+		if !strings.Contains(code, c.want) {
+			t.Errorf("%s: %q is not found in the generated code", c.msg, c.want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("generated code:\n%s", code)
+	}
+}
@@ -0,0 +1,92 @@
+package clientgen
+
+// runtimeCode is the code embedded in the generated package (the imports are written by Generate).
+const runtimeCode = `// Client is the client of the API.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client // if nil, http.DefaultClient is used
+	Header     http.Header  // added to all requests (e.g. Authorization)
+}
+
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: http.Header{}}
+}
+
+// ResponseError is the error of the non-2xx response.
+type ResponseError struct {
+	StatusCode int
+	Body       interface{} // the decoded body (e.g. *Error), if the response is documented
+	RawBody    []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected response: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), bytes.TrimSpace(e.RawBody))
+}
+
+func (c *Client) newRequest(ctx context.Context, method string, path string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+	for k, vs := range c.Header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	return req, nil
+}
+
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+	}
+	return res, nil
+}
+
+func jsonBody(v interface{}) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encode request body: %w", err)
+	}
+	return bytes.NewReader(b), nil
+}
+
+func decodeJSON(res *http.Response, out interface{}) error {
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response body: %w", err)
+	}
+	return nil
+}
+
+// decodeError returns the *ResponseError, the body is decoded into out (if out is not nil).
+func decodeError(res *http.Response, out interface{}) error {
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read response body: %w", err)
+	}
+	resErr := &ResponseError{StatusCode: res.StatusCode, RawBody: b}
+	if out != nil && json.Unmarshal(b, out) == nil {
+		resErr.Body = out
+	}
+	return resErr
+}
+
+func toString(v interface{}) string {
+	if v, ok := v.(encoding.TextMarshaler); ok {
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+`
@@ -0,0 +1,285 @@
+package clientgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
+	"github.com/podhmo/reflect-openapi/walknode"
+)
+
+// reservedNames are the names declared in the runtime code of the generated package.
+var reservedNames = []string{"Client", "New", "ResponseError"}
+
+// resolveNames decides the names of the types of components/schemas and the inputs of the operations.
+func (g *generator) resolveNames() {
+	g.names = map[string]bool{}
+	for _, name := range reservedNames {
+		g.names[name] = true
+	}
+	g.componentNames = map[string]string{}
+	if g.doc.Components != nil {
+		walknode.Schema(g.doc, func(ref *openapi3.SchemaRef, name string) {
+			schema := oasutil.Lookup(g.doc, g.info, ref)
+			if _, _, ok := g.originalType(schema); ok || isDateTime(schema) {
+				return
+			}
+			g.componentNames[name] = oasutil.UniqueName(g.names, goName(name))
+		})
+	}
+}
+
+// typeExpr returns the go type of the schema (e.g. "Pet", "[]Pet", "petstore.Pet", "map[string]int").
+func (g *generator) typeExpr(ref *openapi3.SchemaRef) string {
+	schema := oasutil.Lookup(g.doc, g.info, ref)
+	if schema == nil {
+		return "interface{}"
+	}
+	if typ, ok := g.reuse(schema); ok {
+		return typ
+	}
+	if isDateTime(schema) { // e.g. components/schemas/Time
+		g.useTime = true
+		return "time.Time"
+	}
+	if name := strings.TrimPrefix(ref.Ref, oasutil.ComponentPrefix); name != ref.Ref {
+		if typ, ok := g.componentNames[name]; ok {
+			return typ
+		}
+	}
+	return g.inlineTypeExpr(schema)
+}
+
+func (g *generator) inlineTypeExpr(schema *openapi3.Schema) string {
+	switch schema.Type {
+	case openapi3.TypeString:
+		if schema.Format == "byte" {
+			return "[]byte"
+		}
+		return "string"
+	case openapi3.TypeInteger:
+		switch schema.Format {
+		case "int32":
+			return "int32"
+		case "int64":
+			return "int64"
+		}
+		return "int"
+	case openapi3.TypeNumber:
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case openapi3.TypeBoolean:
+		return "bool"
+	case openapi3.TypeArray:
+		return "[]" + g.typeExpr(schema.Items)
+	}
+
+	if schema.AdditionalProperties.Schema != nil {
+		return "map[string]" + g.typeExpr(schema.AdditionalProperties.Schema)
+	}
+	if len(schema.Properties) > 0 || len(schema.AllOf) > 1 || (len(schema.AllOf) == 1 && len(schema.Properties) > 0) {
+		w := new(bytes.Buffer)
+		w.WriteString("struct {\n")
+		g.writeFields(w, schema)
+		w.WriteString("}")
+		return w.String()
+	}
+	if len(schema.AllOf) == 1 {
+		return g.typeExpr(schema.AllOf[0])
+	}
+	if schema.Type == openapi3.TypeObject {
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func isDateTime(schema *openapi3.Schema) bool {
+	return schema != nil && schema.Type == openapi3.TypeString && schema.Format == "date-time"
+}
+
+// isStruct returns true if the go type of the schema is struct (the output is returned as the pointer).
+func (g *generator) isStruct(ref *openapi3.SchemaRef) bool {
+	schema := oasutil.Lookup(g.doc, g.info, ref)
+	if schema == nil || schema.AdditionalProperties.Schema != nil {
+		return false
+	}
+	if schema.Type != openapi3.TypeObject && schema.Type != "" {
+		return false
+	}
+	return len(schema.Properties) > 0 || len(schema.AllOf) > 0
+}
+
+// writeFields writes the fields of the struct, the order of the properties is the same as the go struct (if info is available).
+func (g *generator) writeFields(w *bytes.Buffer, schema *openapi3.Schema) {
+	seen := map[string]bool{}
+	for _, ref := range schema.AllOf {
+		sub := oasutil.Lookup(g.doc, g.info, ref)
+		if sub == nil {
+			continue
+		}
+		if typ := g.typeExpr(ref); !strings.HasPrefix(typ, "struct {") && g.isStruct(ref) {
+			fmt.Fprintf(w, "%s\n", typ) // embedded
+			continue
+		}
+		g.writeProperties(w, sub, seen)
+	}
+	g.writeProperties(w, schema, seen)
+}
+
+func (g *generator) writeProperties(w *bytes.Buffer, schema *openapi3.Schema, seen map[string]bool) {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	for _, name := range oasutil.OrderedProperties(g.info, schema) {
+		prop := schema.Properties[name]
+		sub := oasutil.Lookup(g.doc, g.info, prop)
+		if sub == nil {
+			continue
+		}
+		if description := sub.Description; description != "" {
+			writeComment(w, "", description)
+		}
+		typ := g.typeExpr(prop)
+		if (sub.Nullable || (!required[name] && g.isStruct(prop))) && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "interface{}" {
+			typ = "*" + typ
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(w, "%s %s `json:%q`\n", oasutil.UniqueName(seen, goName(name)), typ, tag)
+	}
+}
+
+func (g *generator) writeComponents(w *bytes.Buffer) error {
+	if g.doc.Components == nil {
+		return nil
+	}
+	walknode.Schema(g.doc, func(ref *openapi3.SchemaRef, name string) {
+		typeName, ok := g.componentNames[name]
+		if !ok { // reused
+			return
+		}
+		schema := oasutil.Lookup(g.doc, g.info, ref)
+		if schema == nil {
+			return
+		}
+
+		if schema.Description != "" {
+			writeComment(w, "", typeName+" "+strings.TrimPrefix(schema.Description, typeName+" "))
+		}
+		if g.isStruct(&openapi3.SchemaRef{Value: schema}) {
+			fmt.Fprintf(w, "type %s struct {\n", typeName)
+			g.writeFields(w, schema)
+			fmt.Fprintln(w, "}")
+		} else {
+			fmt.Fprintf(w, "type %s %s\n", typeName, g.inlineTypeExpr(schema))
+		}
+		fmt.Fprintln(w)
+	})
+	return nil
+}
+
+// reuse returns the original go type of the schema (and imports its package), if it is importable.
+func (g *generator) reuse(schema *openapi3.Schema) (string, bool) {
+	pkgpath, name, ok := g.originalType(schema)
+	if !ok {
+		return "", false
+	}
+	return g.importAlias(pkgpath) + "." + name, true
+}
+
+// originalType returns the package path and the name of the original go type of the schema (x-go-type, x-go-id or info.SchemaInfo.GoID).
+func (g *generator) originalType(schema *openapi3.Schema) (pkgpath string, name string, ok bool) {
+	if g.DisableReuse || schema == nil {
+		return "", "", false
+	}
+
+	var id string
+	for _, k := range []string{"x-go-type", "x-go-id"} {
+		if v := extensionString(schema.Extensions[k]); v != "" {
+			id = v
+			break
+		}
+	}
+	if id == "" && g.info != nil {
+		if sinfo, ok := g.info.SchemaInfo[schema]; ok {
+			id = sinfo.GoID
+		}
+	}
+	if id == "" || strings.ContainsAny(id, "[]* ") { // e.g. generics
+		return "", "", false
+	}
+
+	slash := strings.LastIndex(id, "/")
+	dot := strings.Index(id[slash+1:], ".")
+	if dot < 0 {
+		return "", "", false
+	}
+	pkgpath, name = id[:slash+1+dot], id[slash+1+dot+1:]
+	if pkgpath == g.PackagePath || !g.IsImportable(pkgpath) || !token.IsExported(name) {
+		return "", "", false
+	}
+	return pkgpath, name, true
+}
+
+// stdImports are the packages imported by the generated code.
+var stdImports = map[string]string{
+	"bytes": "bytes", "context": "context", "encoding": "encoding", "encoding/json": "json", "fmt": "fmt",
+	"io": "io", "net/http": "http", "net/url": "url", "strings": "strings", "time": "time",
+}
+
+func (g *generator) importAlias(pkgpath string) string {
+	if alias, ok := stdImports[pkgpath]; ok {
+		if pkgpath == "time" {
+			g.useTime = true
+		}
+		return alias
+	}
+	if alias, ok := g.imports[pkgpath]; ok {
+		return alias
+	}
+	parts := strings.Split(pkgpath, "/")
+	base := parts[len(parts)-1]
+	if len(parts) > 1 && len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" { // e.g. example.com/foo/v2
+		base = parts[len(parts)-2]
+	}
+	base = strings.ToLower(strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return -1
+	}, base))
+	if base == "" || ('0' <= base[0] && base[0] <= '9') || token.IsKeyword(base) {
+		base = "pkg" + base
+	}
+
+	alias := base
+	for i := 2; g.aliases[alias]; i++ {
+		alias = fmt.Sprintf("%s%d", base, i)
+	}
+	g.aliases[alias] = true
+	g.imports[pkgpath] = alias
+	return alias
+}
+
+func extensionString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.RawMessage:
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package reflectopenapi
+
+import (
+	"log"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ConstraintExtractor extracts the constraints of the field from its struct tag (e.g. `validate:"required,min=1"`).
+type ConstraintExtractor interface {
+	ExtractConstraint(tag reflect.StructTag) *Constraint // if nil, the field does not have any constraints
+}
+
+// Constraint is the set of constraints of the field.
+// Min and Max are the value for numbers, and the size for strings, arrays and maps.
+type Constraint struct {
+	Required bool
+
+	Min          *float64
+	Max          *float64
+	ExclusiveMin bool
+	ExclusiveMax bool
+
+	Enum    []string
+	Format  string
+	Pattern string
+
+	Items *Constraint // constraints for the items of arrays (or the values of maps)
+}
+
+// ValidatorConstraintExtractor is the ConstraintExtractor for github.com/go-playground/validator's tag.
+type ValidatorConstraintExtractor struct {
+	TagName string // default is "validate"
+}
+
+var _ ConstraintExtractor = (*ValidatorConstraintExtractor)(nil)
+
+func (e *ValidatorConstraintExtractor) ExtractConstraint(tag reflect.StructTag) *Constraint {
+	tagName := e.TagName
+	if tagName == "" {
+		tagName = "validate"
+	}
+	v, ok := tag.Lookup(tagName)
+	if !ok || v == "" || v == "-" {
+		return nil
+	}
+
+	root := &Constraint{}
+	c := root
+	inKeys := false
+	for _, x := range strings.Split(v, ",") {
+		if strings.Contains(x, "|") { // or-operator is not supported
+			continue
+		}
+		k, v, _ := strings.Cut(strings.TrimSpace(x), "=")
+		if inKeys { // constraints for the keys of maps are not supported
+			inKeys = k != "endkeys"
+			continue
+		}
+
+		switch k {
+		case "required":
+			c.Required = true
+		case "dive":
+			c.Items = &Constraint{}
+			c = c.Items
+		case "keys":
+			inKeys = true
+		case "min", "gte":
+			c.Min = parseFloat(v)
+		case "max", "lte":
+			c.Max = parseFloat(v)
+		case "gt":
+			c.Min = parseFloat(v)
+			c.ExclusiveMin = true
+		case "lt":
+			c.Max = parseFloat(v)
+			c.ExclusiveMax = true
+		case "len":
+			c.Min = parseFloat(v)
+			c.Max = parseFloat(v)
+		case "oneof":
+			c.Enum = splitOneOf(v)
+		case "email":
+			c.Format = "email"
+		case "uuid", "uuid3", "uuid4", "uuid5":
+			c.Format = "uuid"
+		case "url", "uri":
+			c.Format = "uri"
+		case "hostname":
+			c.Format = "hostname"
+		case "ipv4":
+			c.Format = "ipv4"
+		case "ipv6":
+			c.Format = "ipv6"
+		case "alpha":
+			c.Pattern = "^[a-zA-Z]+$"
+		case "alphanum":
+			c.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric":
+			c.Pattern = "^[-+]?[0-9]+(?:\\.[0-9]+)?$"
+		}
+	}
+	return root
+}
+
+// splitOneOf splits the values of oneof, the value including spaces is quoted by "'" (e.g. oneof='red green' 'blue yellow')
+func splitOneOf(s string) []string {
+	var r []string
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return r
+		}
+		if strings.HasPrefix(s, "'") {
+			if v, rest, ok := strings.Cut(s[1:], "'"); ok {
+				r = append(r, v)
+				s = rest
+				continue
+			}
+		}
+		v, rest, _ := strings.Cut(s, " ")
+		r = append(r, v)
+		s = rest
+	}
+}
+
+func parseFloat(s string) *float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Printf("[WARN]  constraint: %q is not number, ignored", s)
+		return nil
+	}
+	return &v
+}
+
+// applyConstraint applies the constraint to the copied schema, because the schema may be shared with other fields.
+func (t *Transformer) applyConstraint(ref *openapi3.SchemaRef, c *Constraint) *openapi3.SchemaRef {
+	if ref.Ref != "" || ref.Value == nil {
+		log.Printf("[INFO]  constraint: the siblings of $ref are ignored, skip %q", ref.Ref)
+		return ref
+	}
+
+	copied := *ref.Value
+	schema := &copied
+	switch schema.Type {
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		if c.Min != nil {
+			schema.Min = c.Min
+			schema.ExclusiveMin = c.ExclusiveMin
+		}
+		if c.Max != nil {
+			schema.Max = c.Max
+			schema.ExclusiveMax = c.ExclusiveMax
+		}
+	case openapi3.TypeString:
+		if n, ok := toSize(c.Min, c.ExclusiveMin, 1); ok {
+			schema.MinLength = n
+		}
+		if n, ok := toSize(c.Max, c.ExclusiveMax, -1); ok {
+			schema.MaxLength = &n
+		}
+	case openapi3.TypeArray:
+		if n, ok := toSize(c.Min, c.ExclusiveMin, 1); ok {
+			schema.MinItems = n
+		}
+		if n, ok := toSize(c.Max, c.ExclusiveMax, -1); ok {
+			schema.MaxItems = &n
+		}
+	default: // map
+		if n, ok := toSize(c.Min, c.ExclusiveMin, 1); ok {
+			schema.MinProps = n
+		}
+		if n, ok := toSize(c.Max, c.ExclusiveMax, -1); ok {
+			schema.MaxProps = &n
+		}
+	}
+
+	if len(c.Enum) > 0 {
+		schema.Enum = make([]interface{}, 0, len(c.Enum))
+		for _, x := range c.Enum {
+			var v interface{} = x
+			switch schema.Type {
+			case openapi3.TypeInteger:
+				if n, err := strconv.ParseInt(x, 10, 64); err == nil {
+					v = n
+				}
+			case openapi3.TypeNumber:
+				if n, err := strconv.ParseFloat(x, 64); err == nil {
+					v = n
+				}
+			}
+			schema.Enum = append(schema.Enum, v)
+		}
+	}
+	if c.Format != "" {
+		schema.Format = c.Format
+	}
+	if c.Pattern != "" {
+		schema.Pattern = c.Pattern
+	}
+
+	if c.Items != nil {
+		switch {
+		case schema.Items != nil:
+			schema.Items = t.applyConstraint(schema.Items, c.Items)
+		case schema.AdditionalProperties.Schema != nil:
+			schema.AdditionalProperties.Schema = t.applyConstraint(schema.AdditionalProperties.Schema, c.Items)
+		}
+	}
+	return &openapi3.SchemaRef{Value: schema}
+}
+
+func toSize(v *float64, exclusive bool, delta int) (uint64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	n := int(math.Round(*v))
+	if exclusive {
+		n += delta
+	}
+	if n < 0 {
+		n = 0
+	}
+	return uint64(n), true
+}
+
+func contains(xs []string, x string) bool {
+	for _, v := range xs {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
 	"github.com/podhmo/reflect-openapi/walknode"
 )
 
@@ -26,7 +27,8 @@ type Doc struct {
 	HTMLs     []HTMLEndpoint
 	Objects   []Object
 
-	SkipMetadata bool // skip header metadata
+	SkipMetadata bool   // skip header metadata
+	MarkdownURL  string // the link to the markdown text in the html page (optional)
 }
 
 type Endpoint struct {
@@ -35,8 +37,9 @@ type Endpoint struct {
 	OperationID string
 	DocumentInfo
 
-	HtmlID string
-	Tags   string
+	HtmlID   string
+	Tags     string
+	Security string // e.g. "`bearerAuth` ｜ `oauth2 (read:pets)`", "none" if the operation is public
 
 	Input      Object
 	OutputList []Object
@@ -83,15 +86,16 @@ func Generate(doc *openapi3.T, info *info.Info) *Doc {
 			input := Object{Name: "input", TypeString: ActionInputString(doc, info, op)}
 			if body := op.RequestBody; body != nil {
 				if body.Value != nil { // not support request component
-					media := body.Value.Content.Get("application/json")
-					if media != nil {
+					contentType, media := oasutil.RequestBodyMedia(body.Value)
+					input.ContentType = contentType
+					if media != nil && media.Schema != nil {
 						schema, typ := toInnerSchemaAndTypeExpr(info, media.Schema)
 						if sinfo, ok := info.SchemaInfo[schema]; ok {
 							// log.Printf("[DEBUG] schema link: %q link input of %q", typ, op.OperationID)
 							sinfo.Links = append(sinfo.Links, Link{Title: fmt.Sprintf("input of %s as `%s`", op.OperationID, typ), URL: "#" + htmlID})
 						}
 						input.TypeExpr = typ
-						input.HtmlID = toHtmlID(schema.Title) // TODO: name conflict
+						input.HtmlID = toSchemaHtmlID(info, schema)
 					}
 				}
 			}
@@ -109,7 +113,7 @@ func Generate(doc *openapi3.T, info *info.Info) *Doc {
 								sinfo.Links = append(sinfo.Links, Link{Title: fmt.Sprintf("output of %s (%s) as `%s`", op.OperationID, name, typ), URL: "#" + htmlID})
 							}
 							output.TypeExpr = typ
-							output.HtmlID = toHtmlID(schema.Title) // TODO: name conflict
+							output.HtmlID = toSchemaHtmlID(info, schema)
 
 							walknode.Example(media.Examples, func(ref *openapi3.ExampleRef, title string) {
 								b, err := json.MarshalIndent(ref.Value.Value, "", "  ")
@@ -138,6 +142,7 @@ func Generate(doc *openapi3.T, info *info.Info) *Doc {
 				DocumentInfo: toDocumentInfo("", op.Summary, op.Description),
 				HtmlID:       htmlID,
 				Tags:         strings.Join(op.Tags, " "),
+				Security:     toSecurityString(doc, op),
 
 				Input:      input,
 				OutputList: outputList,
@@ -210,6 +215,14 @@ func WriteDoc(w io.Writer, doc *Doc) error {
 	return nil
 }
 
+// toSchemaHtmlID returns the html id of the schema, the name in components/schemas is used if it exists.
+func toSchemaHtmlID(info *info.Info, schema *openapi3.Schema) string {
+	if sinfo, ok := info.SchemaInfo[schema]; ok && sinfo.Name != "" {
+		return toHtmlID(sinfo.Name)
+	}
+	return toHtmlID(schema.Title)
+}
+
 func toInnerSchemaAndTypeExpr(info *info.Info, ref *openapi3.SchemaRef) (*openapi3.Schema, string) {
 	schema := info.LookupSchema(ref)
 	typ := schema.Title
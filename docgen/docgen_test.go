@@ -0,0 +1,58 @@
+package docgen
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/info"
+)
+
+type ListInput struct {
+	Sort Sort `in:"query" query:"sort"`
+}
+
+func List(ListInput) []Person { return nil }
+
+func TestWriteDocInputContentType(t *testing.T) {
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New(), DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Doc.Info.Title = "hello"
+		m.Doc.Info.Version = "0.0.0"
+		m.Post("/hello", Hello)
+		m.Post("/upload", Upload)
+		m.Get("/people", List)
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDoc(&buf, Generate(doc, c.Info)); err != nil {
+		t.Fatalf("WriteDoc(): unexpected error: %+v", err)
+	}
+	got := buf.String()
+
+	cases := []struct {
+		path string
+		want string
+	}{
+		{path: "POST /hello", want: "#### input (application/json)\n"},
+		{path: "POST /upload", want: "#### input (multipart/form-data)\n"},
+		{path: "GET /people", want: "#### input\n"}, // no request body
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.path, func(t *testing.T) {
+			want := c.want + "\n```go\n// " + c.path + "\n"
+			if !strings.Contains(got, want) {
+				t.Errorf("%q is not found", want)
+			}
+		})
+	}
+	if t.Failed() {
+		t.Logf("markdown:\n%s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// WriteHTML writes the self-contained html page of the doc (the markdown is rendered on the server side and the css is embedded, so no network access is needed).
+func WriteHTML(w io.Writer, doc *Doc) error {
+	d := *doc
+	d.SkipMetadata = true
+	buf := new(bytes.Buffer)
+	if err := WriteDoc(buf, &d); err != nil {
+		return err
+	}
+	return WriteHTMLFromMarkdown(w, fmt.Sprintf("%s (%s)", doc.Title, doc.Version), buf.Bytes(), doc.MarkdownURL)
+}
+
+// WriteHTMLFromMarkdown writes the self-contained html page of the markdown text (generated by WriteDoc), the ids of the headings are the same as toHtmlID().
+func WriteHTMLFromMarkdown(w io.Writer, title string, text []byte, markdownURL string) error {
+	tmpl, err := template.ParseFS(fs, "templates/doc.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("lookup template: %w", err)
+	}
+	css, err := fs.ReadFile("templates/style.css")
+	if err != nil {
+		return fmt.Errorf("lookup css: %w", err)
+	}
+
+	if bytes.HasPrefix(text, []byte("---\n")) { // skip header metadata
+		if _, rest, ok := bytes.Cut(text[len("---\n"):], []byte("\n---\n")); ok {
+			text = rest
+		}
+	}
+
+	body := new(bytes.Buffer)
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(html.WithUnsafe()), // e.g. <sub>(source)</sub>
+	)
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{values: map[string]bool{}}))
+	if err := md.Convert(text, body, parser.WithContext(ctx)); err != nil {
+		return fmt.Errorf("render markdown: %w", err)
+	}
+
+	if err := tmpl.Execute(w, struct {
+		Title       string
+		CSS         template.CSS
+		Body        template.HTML
+		MarkdownURL string
+	}{
+		Title:       title,
+		CSS:         template.CSS(css),
+		Body:        template.HTML(body.String()),
+		MarkdownURL: markdownURL,
+	}); err != nil {
+		return fmt.Errorf("write html: %w", err)
+	}
+	return nil
+}
+
+// WriteSite writes the static site into the directory (index.html and doc.md), it can be browsed without network access.
+func WriteSite(dir string, doc *Doc) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create directory: %w", err)
+	}
+
+	d := *doc
+	if d.MarkdownURL == "" {
+		d.MarkdownURL = "doc.md"
+	}
+	for name, write := range map[string]func(io.Writer, *Doc) error{
+		"index.html": WriteHTML,
+		"doc.md":     WriteDoc,
+	} {
+		if err := writeFile(filepath.Join(dir, name), &d, write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(filename string, doc *Doc, write func(io.Writer, *Doc) error) (retErr error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", filename, err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retErr == nil {
+			retErr = fmt.Errorf("close %s: %w", filename, err)
+		}
+	}()
+	return write(f, doc)
+}
+
+// headingIDs generates the ids of the headings with toHtmlID(), for linking from the tables (e.g. "### FindPets `GET /pets`" -> "findpets-get-pets").
+type headingIDs struct {
+	values map[string]bool
+}
+
+func (s *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	id := toHtmlID(string(bytes.TrimSpace(bytes.ReplaceAll(value, []byte("`"), nil))))
+	if id == "" {
+		id = "heading"
+	}
+	if s.values[id] {
+		for i := 1; ; i++ {
+			if candidate := fmt.Sprintf("%s-%d", id, i); !s.values[candidate] {
+				id = candidate
+				break
+			}
+		}
+	}
+	s.values[id] = true
+	return []byte(id)
+}
+
+func (s *headingIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
@@ -0,0 +1,89 @@
+package docgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/info"
+)
+
+func TestWriteHTML(t *testing.T) {
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New(), DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Doc.Info.Title = "hello"
+		m.Doc.Info.Version = "0.0.0"
+		m.Post("/hello", Hello)
+		m.RegisterType(Person{})
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+	d := Generate(doc, c.Info)
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, d); err != nil {
+		t.Fatalf("WriteHTML(): unexpected error: %+v", err)
+	}
+	got := buf.String()
+
+	// the anchors of the headings are linked from the tables
+	var ids []string
+	for _, ep := range d.Endpoints {
+		ids = append(ids, ep.HtmlID)
+	}
+	for _, ob := range d.Objects {
+		ids = append(ids, ob.HtmlID)
+	}
+	if len(ids) < 3 {
+		t.Fatalf("unexpected setup: too few ids %q", ids)
+	}
+	for _, id := range ids {
+		if want := `id="` + id + `"`; !strings.Contains(got, want) {
+			t.Errorf("heading %q is not found", want)
+		}
+		if want := `href="#` + id + `"`; !strings.Contains(got, want) {
+			t.Errorf("link %q is not found", want)
+		}
+	}
+
+	// rendered on the server side, without network access
+	for _, want := range []string{"<title>hello (0.0.0)</title>", "<table>", `<code class="language-go">`, ".markdown-body {"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q is not found", want)
+		}
+	}
+	for _, ng := range []string{"<script", "<link", "https://", "---\ntitle:"} {
+		if strings.Contains(got, ng) {
+			t.Errorf("%q is found, but the html must be self-contained", ng)
+		}
+	}
+	if t.Failed() {
+		t.Logf("html:\n%s", got)
+	}
+
+	t.Run("WriteSite", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "site")
+		if err := WriteSite(dir, d); err != nil {
+			t.Fatalf("WriteSite(): unexpected error: %+v", err)
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, "index.html"))
+		if err != nil {
+			t.Fatalf("index.html: unexpected error: %+v", err)
+		}
+		if want := `<a href="doc.md" id="download-markdown">`; !strings.Contains(string(b), want) {
+			t.Errorf("index.html: %q is not found", want)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "doc.md")); err != nil {
+			t.Errorf("doc.md: unexpected error: %+v", err)
+		}
+		if d.MarkdownURL != "" {
+			t.Errorf("WriteSite() must not modify the doc, but MarkdownURL is %q", d.MarkdownURL)
+		}
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
 )
 
 var PADDING = `	`
@@ -54,21 +55,28 @@ func ActionInputString(doc *openapi3.T, info *info.Info, op *openapi3.Operation)
 			}
 		}
 
-		if body := op.RequestBody; body != nil {
-			if body.Value != nil { // not support request component
+		if body := op.RequestBody; body != nil && body.Value != nil { // not support request component
+			if contentType, media := oasutil.RequestBodyMedia(body.Value); media != nil && media.Schema != nil {
 				if len(op.Parameters) > 0 {
 					w.WriteRune('\n')
 				}
 				if description := body.Value.Description; description != "" {
 					fmt.Fprintf(w, "%s// %s\n", indent, strings.Join(strings.Split(description, "\n"), fmt.Sprintf("\n%s// ", indent)))
 				}
-				fmt.Fprintf(w, "%s%s", indent, "JSONBody")
+				bodyName := "JSONBody"
+				if contentType != "application/json" {
+					bodyName = "FormBody"
+				}
+				fmt.Fprintf(w, "%s%s", indent, bodyName)
 				// if !body.Value.Required {
 				// 	w.WriteRune('?')
 				// }
 				w.WriteRune(' ')
-				schema := info.LookupSchema(body.Value.Content.Get("application/json").Schema)
+				schema := info.LookupSchema(media.Schema)
 				writeType(w, doc, info, schema, []int{-1}, false)
+				if contentType != "application/json" {
+					fmt.Fprintf(w, " `content-type:\"%s\"`", contentType)
+				}
 				w.WriteRune('\n')
 			}
 		}
@@ -122,6 +130,15 @@ func TypeString(doc *openapi3.T, info *info.Info, ref *openapi3.SchemaRef) strin
 }
 
 func writeType(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		writeUnion(w, doc, info, schema, history, showName)
+		return
+	}
+	if len(schema.AllOf) > 0 {
+		writeAllOf(w, doc, info, schema, history, showName)
+		return
+	}
+
 	switch schema.Type {
 	case openapi3.TypeArray:
 		writeArray(w, doc, info, schema, history, showName)
@@ -161,6 +178,24 @@ func writeType(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openap
 	}
 }
 
+func writeUnion(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
+	refs := schema.OneOf
+	if len(refs) == 0 {
+		refs = schema.AnyOf
+	}
+	for i, ref := range refs {
+		if i > 0 {
+			io.WriteString(w, " | ")
+		}
+		subschema := info.LookupSchema(ref)
+		if subschema.Title != "" {
+			io.WriteString(w, subschema.Title)
+			continue
+		}
+		writeType(w, doc, info, subschema, history, showName)
+	}
+}
+
 func writeArray(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
 	// TODO: MinItems,MaxItems,UniqueItems
 	if len(history) > 0 {
@@ -198,6 +233,10 @@ func writeInteger(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *ope
 			}
 		}
 	}
+	if len(schema.Enum) > 0 {
+		writeEnum(w, schema)
+		return
+	}
 	io.WriteString(w, "integer")
 }
 
@@ -215,11 +254,43 @@ func writeNumber(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *open
 }
 
 func writeMap(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
-	io.WriteString(w, "map[string]")
+	keyType := "string"
+	if v, ok := schema.Extensions["x-go-key-type"].(string); ok && v != "" {
+		keyType = v
+	}
+	fmt.Fprintf(w, "map[%s]", keyType)
 	subschema := info.LookupSchema(schema.AdditionalProperties.Schema)
 	writeType(w, doc, info, subschema, history, showName)
 }
 
+// writeAllOf writes the allOf composition as the struct with embedded fields (e.g. struct { Base; name string })
+func writeAllOf(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
+	if len(schema.AllOf) == 1 && schema.Title == "" { // e.g. {"allOf": [{"$ref": "..."}], "readOnly": true}
+		writeType(w, doc, info, info.LookupSchema(schema.AllOf[0]), history, showName)
+		return
+	}
+
+	io.WriteString(w, "struct {")
+	if showName || len(history) > 0 {
+		fmt.Fprintf(w, "%s// %s", PADDING, schema.Title)
+	}
+	w.WriteRune('\n')
+
+	indent := strings.Repeat(PADDING, len(history)+1)
+	for i, ref := range schema.AllOf {
+		subschema := info.LookupSchema(ref)
+		if subschema.Title == "" { // own properties
+			if i > 0 {
+				w.WriteRune('\n')
+			}
+			writeProperties(w, doc, info, subschema, history, showName)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s\n", indent, subschema.Title)
+	}
+	fmt.Fprintf(w, "%s}", strings.Repeat(PADDING, len(history)))
+}
+
 func writeObject(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
 	// TODO: MinProps,MaxProps,(Discriminator)
 
@@ -228,6 +299,11 @@ func writeObject(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *open
 		fmt.Fprintf(w, "%s// %s", PADDING, schema.Title)
 	}
 	w.WriteRune('\n')
+	writeProperties(w, doc, info, schema, history, showName)
+	fmt.Fprintf(w, "%s}", strings.Repeat(PADDING, len(history)))
+}
+
+func writeProperties(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int, showName bool) {
 	meta := info.SchemaInfo[schema]
 	for i, name := range meta.OrderedProperties { // TODO: Nullable,Readonly,WriteOnly,AllowEmptyValue,Deprecated
 		indent := strings.Repeat(PADDING, len(history)+1)
@@ -259,7 +335,6 @@ func writeObject(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *open
 		}
 		w.WriteRune('\n')
 	}
-	fmt.Fprintf(w, "%s}", strings.Repeat(PADDING, len(history)))
 }
 
 func writeString(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *openapi3.Schema, history []int) {
@@ -273,22 +348,50 @@ func writeString(w *bytes.Buffer, doc *openapi3.T, info *info.Info, schema *open
 	}
 
 	if len(schema.Enum) > 0 {
-		values := make([]string, len(schema.Enum))
-		if rt := reflect.TypeOf(schema.Enum[0]); rt.Kind() == reflect.String {
-			for i, x := range schema.Enum {
-				values[i] = strconv.Quote(reflect.ValueOf(x).String())
-			}
-		} else {
-			for i, x := range schema.Enum {
-				values[i] = fmt.Sprintf("%v", x)
-			}
-		}
-		io.WriteString(w, strings.Join(values, " | "))
+		writeEnum(w, schema)
 	} else {
 		io.WriteString(w, "string")
 	}
 }
 
+func writeEnum(w *bytes.Buffer, schema *openapi3.Schema) {
+	values := make([]string, len(schema.Enum))
+	if rt := reflect.TypeOf(schema.Enum[0]); rt.Kind() == reflect.String {
+		for i, x := range schema.Enum {
+			values[i] = strconv.Quote(reflect.ValueOf(x).String())
+		}
+	} else {
+		for i, x := range schema.Enum {
+			values[i] = fmt.Sprintf("%v", x)
+		}
+	}
+	// value/comment pairs (e.g. "asc" /* ascending order */ | "desc" /* descending order */)
+	if descriptions := toStrings(schema.Extensions["x-enum-descriptions"]); len(descriptions) == len(values) {
+		for i, description := range descriptions {
+			if description != "" {
+				values[i] = fmt.Sprintf("%s /* %s */", values[i], strings.Join(strings.Fields(description), " "))
+			}
+		}
+	}
+	io.WriteString(w, strings.Join(values, " | "))
+}
+
+// toStrings converts the value of extensions to []string ([]interface{} if the doc is loaded from file)
+func toStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		r := make([]string, len(v))
+		for i, x := range v {
+			r[i], _ = x.(string)
+		}
+		return r
+	default:
+		return nil
+	}
+}
+
 func writeTags(w *bytes.Buffer, info *info.Info, schema *openapi3.Schema, prefix string) {
 	tags := make([]string, 0, 19)
 
@@ -2,6 +2,7 @@ package docgen
 
 import (
 	"context"
+	"mime/multipart"
 	"strings"
 	"testing"
 
@@ -210,3 +211,113 @@ type Output200 struct {@@// HelloOutput
 		})
 	}
 }
+
+type Timestamp struct {
+	CreatedAt string `json:"createdAt"`
+}
+
+type Article struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+	Timestamp
+}
+
+func TestTypeStringAllOf(t *testing.T) {
+	PADDING = "@@"
+	defer func() { PADDING = "\t" }()
+
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New(), EmbeddedAsAllOf: true}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(Article{})
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+
+	name := "Article"
+	schema := doc.Components.Schemas[name]
+	got := TypeString(doc, c.Info, schema)
+
+	want := `
+type Article struct {
+@@Timestamp
+
+@@title string
+
+@@body? string
+}
+`
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(got)); diff != "" {
+		t.Errorf("TypeString() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"  // visible to everyone
+	VisibilityPrivate Visibility = "private" // visible to the owner only
+	VisibilityDraft   Visibility = "draft"
+)
+
+type Post struct {
+	Visibility Visibility `json:"visibility"`
+}
+
+func TestTypeStringAutoEnum(t *testing.T) {
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New(), EnableAutoEnum: true}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterType(Post{})
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+
+	name := "Visibility"
+	schema := doc.Components.Schemas[name]
+	got := TypeString(doc, c.Info, schema)
+
+	want := `type Visibility "public" /* visible to everyone */ | "private" /* visible to the owner only */ | "draft"`
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(got)); diff != "" {
+		t.Errorf("TypeString() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type UploadInput struct {
+	Name string                `json:"name" in:"form"`
+	File *multipart.FileHeader `json:"file" in:"form"`
+}
+
+func Upload(UploadInput) {}
+
+func TestActionInputStringForm(t *testing.T) {
+	PADDING = "@@"
+	defer func() { PADDING = "\t" }()
+
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New()}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(Upload).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/Upload", "POST", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+
+	op := doc.Paths.Find("/Upload").GetOperation("POST")
+	got := ActionInputString(doc, c.Info, op)
+
+	want := `
+type Input struct {
+@@FormBody struct {@@// UploadInput
+@@@@name string
+
+@@@@file? string ` + "`format:\"binary\"`" + `
+@@} ` + "`content-type:\"multipart/form-data\"`" + `
+}
+`
+
+	if diff := cmp.Diff(strings.TrimSpace(want), strings.TrimSpace(got)); diff != "" {
+		t.Errorf("ActionInputString() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -3,7 +3,10 @@ package docgen
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 var (
@@ -54,3 +57,41 @@ func toHtmlID(s string, xs ...string) string {
 	s = toDashRegex.ReplaceAllString(s, "-")
 	return s
 }
+
+// toSecurityString returns the security requirement of the operation (the global one is used, if the operation does not have it).
+func toSecurityString(doc *openapi3.T, op *openapi3.Operation) string {
+	requirements := doc.Security
+	if op.Security != nil {
+		requirements = *op.Security
+	}
+	if len(requirements) == 0 {
+		if op.Security != nil && len(doc.Security) > 0 {
+			return "none"
+		}
+		return ""
+	}
+
+	alternatives := make([]string, 0, len(requirements))
+	for _, req := range requirements {
+		names := make([]string, 0, len(req))
+		for name := range req {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		schemes := make([]string, 0, len(names))
+		for _, name := range names {
+			if scopes := req[name]; len(scopes) > 0 {
+				schemes = append(schemes, fmt.Sprintf("`%s (%s)`", name, strings.Join(scopes, ", ")))
+			} else {
+				schemes = append(schemes, fmt.Sprintf("`%s`", name))
+			}
+		}
+		if len(schemes) == 0 {
+			alternatives = append(alternatives, "none") // {} means optional
+			continue
+		}
+		alternatives = append(alternatives, strings.Join(schemes, " + "))
+	}
+	return strings.Join(alternatives, " ｜ ")
+}
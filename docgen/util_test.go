@@ -3,6 +3,7 @@ package docgen
 import (
 	"testing"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -54,3 +55,27 @@ func Test_toDocumentInfo(t *testing.T) {
 		})
 	}
 }
+
+func Test_toSecurityString(t *testing.T) {
+	bearer := openapi3.SecurityRequirements{{"bearerAuth": []string{}}}
+	tests := []struct {
+		name   string
+		global openapi3.SecurityRequirements
+		op     *openapi3.SecurityRequirements
+		want   string
+	}{
+		{"nothing", nil, nil, ""},
+		{"global", bearer, nil, "`bearerAuth`"},
+		{"no-security", bearer, &openapi3.SecurityRequirements{}, "none"},
+		{"scopes", nil, &openapi3.SecurityRequirements{{"oauth2": []string{"read:pets", "write:pets"}}}, "`oauth2 (read:pets, write:pets)`"},
+		{"alternatives", bearer, &openapi3.SecurityRequirements{{"bearerAuth": []string{}}, {"apiKey": []string{}, "session": []string{}}}, "`bearerAuth` ｜ `apiKey` + `session`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toSecurityString(&openapi3.T{Security: tt.global}, &openapi3.Operation{Security: tt.op})
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("toSecurityString() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
 	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/info"
 )
 
 // Hello world
@@ -73,3 +75,34 @@ func TestEndpoints(t *testing.T) {
 		t.Errorf("handler response mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestMdDoc(t *testing.T) {
+	c := &reflectopenapi.Config{SkipExtractComments: true, Info: info.New()}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Post("/hello", Hello)
+	})
+	if err != nil {
+		t.Fatalf("unexpected setup failure: %+v", err)
+	}
+	handler := New(doc, "/_doc", c.Info, "")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/_doc/mddoc", nil))
+	res := rec.Result()
+
+	if want, got := 200, res.StatusCode; want != got {
+		t.Errorf("unexpoected status code: want:%d, but got:%d", want, got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`<a href="mddoc.md" id="download-markdown">`, `<h3 id="githubcompodhmoreflect-openapidochandlerhello-post-hello">`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("%q is not found in the response", want)
+		}
+	}
+	// served without network access
+	for _, ng := range []string{"esm.sh", "cdnjs", "<script"} {
+		if strings.Contains(body, ng) {
+			t.Errorf("%q is found in the response", ng)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package dochandler
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"net/http"
@@ -25,23 +26,30 @@ type MDDocHandler struct {
 
 	once sync.Once
 	text string
+	html []byte // rendered on the server side, for serving without network access
 	err  error
 }
 
 func (h *MDDocHandler) init(doc *openapi3.T, info *info.Info) {
 	h.once.Do(func() {
-		if h.text != "" {
-			return
+		if h.text == "" {
+			mddoc := docgen.Generate(doc, info)
+			mddoc.SkipMetadata = true
+			buf := new(strings.Builder)
+			if err := docgen.WriteDoc(buf, mddoc); err != nil {
+				h.err = err
+				return
+			}
+			h.text = buf.String()
 		}
 
-		mddoc := docgen.Generate(doc, info)
-		mddoc.SkipMetadata = true
-		buf := new(strings.Builder)
-		if err := docgen.WriteDoc(buf, mddoc); err != nil {
+		title := fmt.Sprintf("%s (%s)", doc.Info.Title, doc.Info.Version)
+		buf := new(bytes.Buffer)
+		if err := docgen.WriteHTMLFromMarkdown(buf, title, []byte(h.text), "mddoc.md"); err != nil {
 			h.err = err
 			return
 		}
-		h.text = buf.String()
+		h.html = buf.Bytes()
 	})
 }
 
@@ -53,10 +61,8 @@ func (h *MDDocHandler) HTML(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	doc := h.doc
-	text := h.text
-	title := fmt.Sprintf("%s (%s)", doc.Info.Title, doc.Info.Version)
-	fmt.Fprintf(w, MDDOC_TEMPLATE, title, text)
+	w.Header().Add("Content-Type", "text/html; charset=utf-8")
+	w.Write(h.html)
 }
 
 func (h *MDDocHandler) Text(w http.ResponseWriter, r *http.Request) {
@@ -80,6 +86,9 @@ func (h *MDDocHandler) Text(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, text)
 }
 
+// MDDOC_TEMPLATE renders the markdown in the browser.
+//
+// Deprecated: this template loads the scripts and the css from esm.sh and cdnjs, MDDocHandler.HTML uses docgen.WriteHTMLFromMarkdown() instead.
 const MDDOC_TEMPLATE = `<!DOCTYPE html>
 <html lang="ja">
 <meta charset="UTF-8">
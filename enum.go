@@ -0,0 +1,202 @@
+package reflectopenapi
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	shape "github.com/podhmo/reflect-shape"
+	"golang.org/x/tools/go/packages"
+)
+
+// EnumValue is the value of the typed constant (e.g. const SortOrderAsc SortOrder = "asc")
+type EnumValue struct {
+	Name  string      // the name of the constant (e.g. SortOrderAsc)
+	Value interface{} // string, int64, uint64, float64 or bool
+	Doc   string      // the doc comment of the constant
+}
+
+// EnumLookup finds the typed constants of the named type, for detecting enum automatically.
+type EnumLookup struct {
+	Fset *token.FileSet
+
+	cache  map[string][]*packages.Package // pkgpath -> loaded packages
+	values map[reflect.Type][]EnumValue
+}
+
+func NewEnumLookup(fset *token.FileSet) *EnumLookup {
+	if fset == nil {
+		fset = token.NewFileSet()
+	}
+	return &EnumLookup{Fset: fset, cache: map[string][]*packages.Package{}, values: map[reflect.Type][]EnumValue{}}
+}
+
+// LookupEnum returns the typed constants of rt, in order of the declaration.
+func (l *EnumLookup) LookupEnum(rt reflect.Type) ([]EnumValue, error) {
+	if values, ok := l.values[rt]; ok {
+		return values, nil
+	}
+	values, err := l.lookupEnum(rt)
+	if err != nil {
+		return nil, err
+	}
+	l.values[rt] = values
+	return values, nil
+}
+
+func (l *EnumLookup) lookupEnum(rt reflect.Type) ([]EnumValue, error) {
+	name, _, _ := strings.Cut(rt.Name(), "[") // for generics
+	pkgpath := rt.PkgPath()
+	if name == "" || pkgpath == "" {
+		return nil, nil
+	}
+	if pkgpath == "main" {
+		binfo, ok := debug.ReadBuildInfo()
+		if !ok {
+			return nil, fmt.Errorf("debug.ReadBuildInfo() is failed")
+		}
+		pkgpath = binfo.Path
+	}
+
+	pkgs, err := l.load(pkgpath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.PkgPath != pkgpath && !(rt.PkgPath() == "main" && pkg.Name == "main") {
+			continue
+		}
+
+		var values []EnumValue
+		for _, f := range pkg.Syntax {
+			for _, decl := range f.Decls {
+				decl, ok := decl.(*ast.GenDecl)
+				if !ok || decl.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					spec := spec.(*ast.ValueSpec)
+					for _, ident := range spec.Names {
+						c, ok := pkg.TypesInfo.Defs[ident].(*types.Const)
+						if !ok {
+							continue
+						}
+						named, ok := c.Type().(*types.Named)
+						if !ok || named.Obj().Name() != name || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != pkg.PkgPath {
+							continue
+						}
+						doc := spec.Doc.Text()
+						if doc == "" {
+							doc = spec.Comment.Text()
+						}
+						if doc == "" && len(decl.Specs) == 1 {
+							doc = decl.Doc.Text()
+						}
+						values = append(values, EnumValue{Name: c.Name(), Value: constantValue(c.Val()), Doc: strings.TrimSpace(doc)})
+					}
+				}
+			}
+		}
+		if len(values) > 0 {
+			return values, nil
+		}
+	}
+	return nil, nil
+}
+
+func (l *EnumLookup) load(pkgpath string) ([]*packages.Package, error) {
+	if pkgs, ok := l.cache[pkgpath]; ok {
+		return pkgs, nil
+	}
+
+	cfg := &packages.Config{
+		Fset: l.Fset,
+		// NeedImports and NeedDeps are for type-checking the constants with the imported types (e.g. SortOrder = SortOrder(sort.Asc))
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Tests: true, // for the types defined in *_test.go
+	}
+	pkgs, err := packages.Load(cfg, strings.TrimSuffix(pkgpath, "_test"))
+	if err != nil {
+		return nil, fmt.Errorf("packages.Load() %w", err)
+	}
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			log.Printf("[WARN]  enum: lookup package error (%s) %+v", pkg, err)
+		}
+	}
+	l.cache[pkgpath] = pkgs
+	return pkgs, nil
+}
+
+// transformEnum returns the schema with enum, if two or more typed constants of the type are found.
+// (a single constant is not treated as enum, e.g. const DefaultTimeout Seconds = 30)
+func (t *Transformer) transformEnum(s *shape.Shape) *openapi3.Schema {
+	values, err := t.EnumLookup.LookupEnum(s.Type)
+	if err != nil {
+		log.Printf("[WARN]  enum: lookup %v is failed: %+v", s.Type, err)
+		return nil
+	}
+	if len(values) < 2 {
+		return nil
+	}
+
+	var schema *openapi3.Schema
+	switch s.Kind {
+	case reflect.String:
+		schema = openapi3.NewStringSchema()
+	case reflect.Float32, reflect.Float64:
+		schema = openapi3.NewFloat64Schema()
+	default:
+		schema = openapi3.NewIntegerSchema()
+	}
+	schema.Title = s.Name
+	if doc := s.Named().Doc(); doc != "" {
+		schema.Description = doc
+	}
+
+	varnames := make([]string, len(values))
+	descriptions := make([]string, len(values))
+	hasDescription := false
+	for i, v := range values {
+		schema.Enum = append(schema.Enum, v.Value)
+		varnames[i] = v.Name
+		descriptions[i] = v.Doc
+		hasDescription = hasDescription || v.Doc != ""
+	}
+	schema.Extensions = map[string]interface{}{
+		t.TagNameOption.XNewTypeTag: s.FullName(),
+		"x-enum-varnames":           varnames,
+	}
+	if hasDescription {
+		schema.Extensions["x-enum-descriptions"] = descriptions
+	}
+	return schema
+}
+
+func constantValue(v constant.Value) interface{} {
+	switch v.Kind() {
+	case constant.String:
+		return constant.StringVal(v)
+	case constant.Bool:
+		return constant.BoolVal(v)
+	case constant.Int:
+		if n, ok := constant.Int64Val(v); ok {
+			return n
+		}
+		n, _ := constant.Uint64Val(v)
+		return n
+	case constant.Float:
+		n, _ := constant.Float64Val(v)
+		return n
+	default:
+		return v.ExactString()
+	}
+}
@@ -0,0 +1,160 @@
+package reflectopenapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ErrorDef is the definition of the error response, for the error value (sentinel) or the error type.
+type ErrorDef struct {
+	Err         error       // the sentinel value (e.g. ErrNotFound), or the zero value of the error type (e.g. &ValidationError{}) that matches any value of the type
+	Code        int         // the status code (e.g. 404)
+	Description string      // the description of the response
+	Body        interface{} // the value of the response body (e.g. ErrorResponse{}), if nil, Config.DefaultError is used
+	Example     interface{} // the example of the response body
+}
+
+// Name returns the name of the error, used as the title of the example.
+func (d *ErrorDef) Name() string {
+	if d.matchesType() {
+		return strings.TrimPrefix(reflect.TypeOf(d.Err).String(), "*")
+	}
+	return d.Err.Error()
+}
+
+func (d *ErrorDef) matchesType() bool {
+	rv := reflect.ValueOf(d.Err)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	return rv.IsZero()
+}
+
+// WithBody sets the value of the response body.
+func (d *ErrorDef) WithBody(body interface{}) *ErrorDef {
+	d.Body = body
+	return d
+}
+
+// WithExample sets the example of the response body.
+func (d *ErrorDef) WithExample(example interface{}) *ErrorDef {
+	d.Example = example
+	return d
+}
+
+// ErrorCatalog is the table of the errors and the responses, it can be shared with the handlers for mapping the errors at runtime.
+type ErrorCatalog struct {
+	defs []*ErrorDef
+}
+
+func NewErrorCatalog() *ErrorCatalog {
+	return &ErrorCatalog{}
+}
+
+// Register registers the error with the status code and the description.
+func (c *ErrorCatalog) Register(err error, code int, description string) *ErrorDef {
+	if err == nil {
+		panic("ErrorCatalog.Register: err is nil")
+	}
+	def := &ErrorDef{Err: err, Code: code, Description: description}
+	c.defs = append(c.defs, def)
+	return def
+}
+
+// Defs returns the registered definitions, in order of registration.
+func (c *ErrorCatalog) Defs() []*ErrorDef {
+	return c.defs
+}
+
+// Lookup returns the definition of the error, the wrapped errors are also checked (like errors.Is() and errors.As()).
+// This is for mapping the errors at runtime (e.g. in the error handler of the router).
+func (c *ErrorCatalog) Lookup(err error) (*ErrorDef, bool) {
+	for err != nil {
+		if def, ok := c.lookup(err); ok {
+			return def, true
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil, false
+}
+
+func (c *ErrorCatalog) lookup(err error) (*ErrorDef, bool) {
+	rt := reflect.TypeOf(err)
+	for _, def := range c.defs {
+		if reflect.TypeOf(def.Err) != rt {
+			continue
+		}
+		if rt.Comparable() && def.Err == err {
+			return def, true
+		}
+	}
+	for _, def := range c.defs {
+		if reflect.TypeOf(def.Err) == rt && def.matchesType() {
+			return def, true
+		}
+	}
+	return nil, false
+}
+
+// errorResponses builds the responses of the errors, the errors that have the same status code are merged into one response.
+func (m *Manager) errorResponses(errs []error) (map[string]*openapi3.ResponseRef, error) {
+	grouped := map[int][]*ErrorDef{}
+	for _, err := range errs {
+		def, ok := m.ErrorCatalog.Lookup(err)
+		if !ok {
+			return nil, fmt.Errorf("error %q (%T) is not registered in ErrorCatalog", err, err)
+		}
+		grouped[def.Code] = append(grouped[def.Code], def)
+	}
+
+	codes := make([]int, 0, len(grouped))
+	for code := range grouped {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+
+	responses := make(map[string]*openapi3.ResponseRef, len(grouped))
+	for _, code := range codes {
+		defs := grouped[code]
+		descriptions := make([]string, 0, len(defs))
+		for _, def := range defs {
+			descriptions = append(descriptions, def.Description)
+		}
+		res := openapi3.NewResponse().WithDescription(strings.Join(descriptions, "\n"))
+
+		body := defs[0].Body
+		if body == nil {
+			body = m.defaultError
+		}
+		if body != nil {
+			errSchema := m.Visitor.VisitType(m.Visitor.Extractor.Extract(body))
+			res.WithJSONSchemaRef(errSchema)
+
+			media := res.Content.Get("application/json")
+			for _, def := range defs {
+				if def.Body != nil && reflect.TypeOf(def.Body) != reflect.TypeOf(body) {
+					log.Printf("[WARN]  the body of %q (%T) is ignored, because the response of %d uses %T", def.Name(), def.Body, code, body)
+				}
+				if def.Example == nil {
+					continue
+				}
+				if media.Examples == nil {
+					media.Examples = openapi3.Examples{}
+				}
+				media.Examples[def.Name()] = &openapi3.ExampleRef{Value: &openapi3.Example{
+					Summary: def.Description,
+					Value:   def.Example,
+				}}
+			}
+		}
+		responses[strconv.Itoa(code)] = &openapi3.ResponseRef{Value: res}
+	}
+	return responses, nil
+}
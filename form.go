@@ -0,0 +1,133 @@
+package reflectopenapi
+
+import (
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	shape "github.com/podhmo/reflect-shape"
+)
+
+const (
+	ContentTypeJSON           = "application/json"
+	ContentTypeMultipartForm  = "multipart/form-data"
+	ContentTypeURLEncodedForm = "application/x-www-form-urlencoded"
+)
+
+// fileTypes are transformed as {"type": "string", "format": "binary"}, and the request body including them is multipart/form-data.
+var fileTypes = []reflect.Type{
+	reflect.TypeOf(multipart.FileHeader{}),
+	reflect.TypeOf(func(multipart.File) {}).In(0),
+	reflect.TypeOf(func(io.Reader) {}).In(0),
+	reflect.TypeOf(func(io.ReadCloser) {}).In(0),
+}
+
+func isFileType(rt reflect.Type) bool {
+	for rt.Kind() == reflect.Pointer || rt.Kind() == reflect.Slice || rt.Kind() == reflect.Array {
+		if rt.Kind() != reflect.Pointer && rt.Elem().Kind() == reflect.Uint8 { // []byte
+			return false
+		}
+		rt = rt.Elem()
+	}
+	for _, ft := range fileTypes {
+		if rt == ft {
+			return true
+		}
+	}
+	return false
+}
+
+// isFormParamType returns true if the value of ParamTypeTag is "form" or "formData".
+func isFormParamType(paramType string) bool {
+	switch strings.ToLower(paramType) {
+	case "form", "formdata":
+		return true
+	default:
+		return false
+	}
+}
+
+// requestContentType decides the content type of the request body of the function.
+// if not specified by RegisterFuncAction.RequestContentType(), the body is multipart/form-data if the input has file fields, application/x-www-form-urlencoded if it has `in:"form"` fields, otherwise application/json.
+func (t *Transformer) requestContentType(fn *shape.Shape, inob *shape.Shape, schema *openapi3.Schema) string {
+	if mime, ok := t.requestContentTypes[fn.Number]; ok {
+		return mime
+	}
+
+	isForm := false
+	for _, f := range flattenFieldsWithValue(inob.Struct().Fields(), newValue(inob.Type)) {
+		if isFileType(f.Shape.Type) {
+			return ContentTypeMultipartForm
+		}
+		if v, ok := f.Tag.Lookup(t.TagNameOption.ParamTypeTag); ok && isFormParamType(v) {
+			isForm = true
+		}
+	}
+	if !isForm {
+		return ContentTypeJSON
+	}
+	for _, prop := range schema.Properties {
+		if isBinarySchema(prop.Value) {
+			return ContentTypeMultipartForm
+		}
+	}
+	return ContentTypeURLEncodedForm
+}
+
+// formEncoding returns the encoding object of the form body, e.g. {"avatar": {"contentType": "image/png"}}
+func (t *Transformer) formEncoding(inob *shape.Shape, schema *openapi3.Schema, mime string) map[string]*openapi3.Encoding {
+	explicit := map[string]string{}
+	for _, f := range flattenFieldsWithValue(inob.Struct().Fields(), newValue(inob.Type)) {
+		v, ok := f.Tag.Lookup(t.TagNameOption.ContentTypeTag)
+		if !ok {
+			continue
+		}
+		explicit[t.formFieldName(f.Field)] = v
+	}
+
+	encoding := map[string]*openapi3.Encoding{}
+	for name, prop := range schema.Properties {
+		contentType := explicit[name]
+		if contentType == "" && mime == ContentTypeMultipartForm && prop.Value != nil {
+			switch {
+			case isBinarySchema(prop.Value):
+				contentType = "application/octet-stream"
+			case prop.Value.Type == "object" || (prop.Value.Type == "array" && prop.Value.Items != nil && prop.Value.Items.Value != nil && prop.Value.Items.Value.Type == "object"):
+				contentType = ContentTypeJSON
+			}
+		}
+		if contentType != "" {
+			encoding[name] = &openapi3.Encoding{ContentType: contentType}
+		}
+	}
+	if len(encoding) == 0 {
+		return nil
+	}
+	return encoding
+}
+
+// formFieldName returns the name of the field in the form body (`form:"name"` is prior to `json:"name"`).
+func (t *Transformer) formFieldName(f *shape.Field) string {
+	name := f.Name
+	if v, ok := f.Tag.Lookup(t.TagNameOption.NameTag); ok {
+		name, _, _ = strings.Cut(v, ",")
+	}
+	if v, ok := f.Tag.Lookup(t.TagNameOption.ParamTypeTag); ok && isFormParamType(v) {
+		if v, ok := f.Tag.Lookup("form"); ok {
+			name, _, _ = strings.Cut(v, ",")
+		}
+	}
+	return name
+}
+
+func isBinarySchema(s *openapi3.Schema) bool {
+	if s == nil {
+		return false
+	}
+	if s.Type == "string" && s.Format == "binary" {
+		return true
+	}
+	return s.Type == "array" && s.Items != nil && isBinarySchema(s.Items.Value)
+}
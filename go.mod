@@ -7,6 +7,8 @@ require (
 	github.com/google/go-cmp v0.5.9
 	github.com/perimeterx/marshmallow v1.1.5
 	github.com/podhmo/reflect-shape v0.4.3
+	github.com/yuin/goldmark v1.6.0
+	golang.org/x/tools v0.12.0
 )
 
 require (
@@ -20,6 +22,5 @@ require (
 	github.com/rogpeppe/go-internal v1.11.0 // indirect
 	golang.org/x/mod v0.12.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
-	golang.org/x/tools v0.12.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -31,6 +31,7 @@ type SchemaInfo struct {
 	OrderedProperties []string
 	Links             []Link
 	Name              string // not zero value if the schema is existed in components/schemas
+	GoID              string // the full name of the go type (e.g. "github.com/foo/bar.User"), not zero value if the schema is existed in components/schemas
 }
 
 func (i *SchemaInfo) IsExported() bool {
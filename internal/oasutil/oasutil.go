@@ -0,0 +1,104 @@
+// Package oasutil is the helpers for reading the generated openapi doc, shared by the generators (clientgen, tsgen, docgen).
+package oasutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/podhmo/reflect-openapi/info"
+)
+
+// ComponentPrefix is the prefix of the $ref to components/schemas.
+const ComponentPrefix = "#/components/schemas/"
+
+// SuccessStatus returns the lowest 2xx status code of the responses.
+func SuccessStatus(responses openapi3.Responses) (int, bool) {
+	found := 0
+	for name, ref := range responses {
+		code, err := strconv.Atoi(name)
+		if err != nil || code < 200 || code >= 300 || ref == nil || ref.Value == nil {
+			continue
+		}
+		if found == 0 || code < found {
+			found = code
+		}
+	}
+	return found, found != 0
+}
+
+// SuccessResponse returns the status code and the response of the lowest 2xx response (see SuccessStatus).
+func SuccessResponse(op *openapi3.Operation) (int, *openapi3.Response) {
+	code, ok := SuccessStatus(op.Responses)
+	if !ok {
+		return 0, nil
+	}
+	return code, op.Responses.Get(code).Value
+}
+
+// RequestBodyMedia returns the content type and the media type of the request body, "application/json" is preferred.
+// If the request body has no content, the media type is nil.
+func RequestBodyMedia(body *openapi3.RequestBody) (string, *openapi3.MediaType) {
+	for _, contentType := range []string{"application/json", "multipart/form-data", "application/x-www-form-urlencoded"} {
+		if media := body.Content.Get(contentType); media != nil {
+			return contentType, media
+		}
+	}
+	contentTypes := make([]string, 0, len(body.Content))
+	for k := range body.Content {
+		contentTypes = append(contentTypes, k)
+	}
+	if len(contentTypes) == 0 {
+		return "", nil
+	}
+	sort.Strings(contentTypes)
+	return contentTypes[0], body.Content[contentTypes[0]]
+}
+
+// UniqueName returns the name not included in seen (e.g. "Pet", "Pet2", "Pet3"), and marks it as seen.
+func UniqueName(seen map[string]bool, name string) string {
+	candidate := name
+	for i := 2; seen[candidate]; i++ {
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	seen[candidate] = true
+	return candidate
+}
+
+// Lookup returns the schema of the ref, the $ref is resolved with the info or the components of the doc.
+func Lookup(doc *openapi3.T, info *info.Info, ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value != nil {
+		return ref.Value
+	}
+	if info != nil {
+		if v := info.LookupSchema(ref); v != nil {
+			return v
+		}
+	}
+	if name := strings.TrimPrefix(ref.Ref, ComponentPrefix); name != ref.Ref && doc.Components != nil {
+		if def, ok := doc.Components.Schemas[name]; ok {
+			return def.Value
+		}
+	}
+	return nil
+}
+
+// OrderedProperties returns the property names of the schema, in the order of the struct fields if it is known (otherwise sorted).
+func OrderedProperties(info *info.Info, schema *openapi3.Schema) []string {
+	if info != nil {
+		if sinfo, ok := info.SchemaInfo[schema]; ok && len(sinfo.OrderedProperties) == len(schema.Properties) {
+			return sinfo.OrderedProperties
+		}
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
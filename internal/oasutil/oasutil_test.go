@@ -0,0 +1,63 @@
+package oasutil
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestSuccessStatus(t *testing.T) {
+	cases := []struct {
+		name  string
+		codes []string
+		want  int
+		ok    bool
+	}{
+		{name: "200", codes: []string{"200", "default"}, want: 200, ok: true},
+		{name: "lowest", codes: []string{"204", "201", "400"}, want: 201, ok: true},
+		{name: "range", codes: []string{"2XX", "202"}, want: 202, ok: true},
+		{name: "no-2xx", codes: []string{"default", "404"}, ok: false},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			responses := openapi3.Responses{}
+			for _, code := range c.codes {
+				responses[code] = &openapi3.ResponseRef{Value: openapi3.NewResponse()}
+			}
+			got, ok := SuccessStatus(responses)
+			if c.want != got || c.ok != ok {
+				t.Errorf("SuccessStatus(%v): want (%d, %v), but got (%d, %v)", c.codes, c.want, c.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestRequestBodyMedia(t *testing.T) {
+	cases := []struct {
+		name         string
+		contentTypes []string
+		want         string
+	}{
+		{name: "json", contentTypes: []string{"text/plain", "application/json", "multipart/form-data"}, want: "application/json"},
+		{name: "form", contentTypes: []string{"application/x-www-form-urlencoded", "multipart/form-data"}, want: "multipart/form-data"},
+		{name: "other", contentTypes: []string{"text/plain", "application/octet-stream"}, want: "application/octet-stream"},
+		{name: "empty", want: ""},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			body := openapi3.NewRequestBody().WithContent(openapi3.Content{})
+			for _, contentType := range c.contentTypes {
+				body.Content[contentType] = openapi3.NewMediaType()
+			}
+			got, media := RequestBodyMedia(body)
+			if c.want != got {
+				t.Errorf("RequestBodyMedia(): want %q, but got %q", c.want, got)
+			}
+			if (media == nil) != (c.want == "") {
+				t.Errorf("RequestBodyMedia(): unexpected media %+v", media)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package reflectopenapi
+
+import (
+	"strings"
+	"unicode"
+
+	shape "github.com/podhmo/reflect-shape"
+)
+
+// SchemaNamer returns the name of the schema in components/schemas.
+// name is the title of the schema or the name of the type (e.g. "PaginatedOutput[[]github.com/foo/bar.User]").
+type SchemaNamer func(name string, s *shape.Shape) string
+
+var (
+	_ SchemaNamer = DefaultSchemaNamer
+	_ SchemaNamer = CamelCaseSchemaNamer
+	_ SchemaNamer = UnderscoreSchemaNamer
+	_ SchemaNamer = QualifiedSchemaNamer
+)
+
+// DefaultSchemaNamer replaces "[" with "_" and drops "]". (e.g. PaginatedOutput[[]main.User] -> PaginatedOutput__main.User)
+func DefaultSchemaNamer(name string, s *shape.Shape) string {
+	if strings.Contains(name, "[") {
+		name = strings.ReplaceAll(name, "[", "_")
+	}
+	if strings.Contains(name, "]") {
+		name = strings.ReplaceAll(name, "]", "")
+	}
+	return name
+}
+
+// CamelCaseSchemaNamer concatenates the type arguments without package names. (e.g. PaginatedOutput[[]main.User] -> PaginatedOutputUserList)
+func CamelCaseSchemaNamer(name string, s *shape.Shape) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	expr, _ := parseTypeExpr(name)
+	return expr.format("", false)
+}
+
+// UnderscoreSchemaNamer joins the type arguments with "_", without package names. (e.g. PaginatedOutput[[]main.User] -> PaginatedOutput_UserList)
+func UnderscoreSchemaNamer(name string, s *shape.Shape) string {
+	if !strings.Contains(name, "[") {
+		return name
+	}
+	expr, _ := parseTypeExpr(name)
+	return expr.format("_", false)
+}
+
+// QualifiedSchemaNamer is similar to UnderscoreSchemaNamer, but the names are qualified with package names. (e.g. PaginatedOutput[[]main.User] -> main.PaginatedOutput_main.UserList)
+func QualifiedSchemaNamer(name string, s *shape.Shape) string {
+	expr, _ := parseTypeExpr(name)
+	if expr.kind == typeExprNamed && expr.pkg == "" && s.Package != nil {
+		expr.pkg = s.Package.Path
+	}
+	return expr.format("_", true)
+}
+
+type typeExprKind int
+
+const (
+	typeExprNamed typeExprKind = iota
+	typeExprSlice
+	typeExprPointer
+	typeExprMap
+)
+
+// typeExpr is the parsed type expression of reflect.Type.String() (or Name())
+type typeExpr struct {
+	kind typeExprKind
+	pkg  string // package path (only for named)
+	name string // (only for named)
+	args []*typeExpr
+}
+
+func parseTypeExpr(s string) (*typeExpr, string) {
+	switch {
+	case strings.HasPrefix(s, "[]"):
+		elem, rest := parseTypeExpr(s[2:])
+		return &typeExpr{kind: typeExprSlice, args: []*typeExpr{elem}}, rest
+	case strings.HasPrefix(s, "["): // array
+		if _, rest, ok := strings.Cut(s, "]"); ok {
+			elem, rest := parseTypeExpr(rest)
+			return &typeExpr{kind: typeExprSlice, args: []*typeExpr{elem}}, rest
+		}
+	case strings.HasPrefix(s, "*"):
+		elem, rest := parseTypeExpr(s[1:])
+		return &typeExpr{kind: typeExprPointer, args: []*typeExpr{elem}}, rest
+	case strings.HasPrefix(s, "map["):
+		key, rest := parseTypeExpr(s[4:])
+		rest = strings.TrimPrefix(rest, "]")
+		val, rest := parseTypeExpr(rest)
+		return &typeExpr{kind: typeExprMap, args: []*typeExpr{key, val}}, rest
+	}
+
+	end := strings.IndexAny(s, "[],")
+	if end < 0 {
+		end = len(s)
+	}
+	expr := &typeExpr{kind: typeExprNamed, name: s[:end]}
+	if i := strings.LastIndex(expr.name, "."); i >= 0 {
+		expr.pkg = expr.name[:i]
+		expr.name = expr.name[i+1:]
+	}
+	if name, _, ok := strings.Cut(expr.name, "·"); ok { // local type (e.g. Item·1)
+		expr.name = name
+	}
+
+	rest := s[end:]
+	if strings.HasPrefix(rest, "[") { // type arguments
+		rest = rest[1:]
+		for rest != "" {
+			var arg *typeExpr
+			arg, rest = parseTypeExpr(rest)
+			expr.args = append(expr.args, arg)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimLeft(rest[1:], " ")
+				continue
+			}
+			rest = strings.TrimPrefix(rest, "]")
+			break
+		}
+	}
+	return expr, rest
+}
+
+func (e *typeExpr) format(sep string, qualified bool) string {
+	switch e.kind {
+	case typeExprSlice:
+		return e.args[0].formatArg(sep, qualified) + "List"
+	case typeExprPointer:
+		return e.args[0].format(sep, qualified)
+	case typeExprMap:
+		return e.args[0].formatArg(sep, qualified) + e.args[1].formatArg(sep, qualified) + "Map"
+	}
+
+	var b strings.Builder
+	if qualified && e.pkg != "" {
+		parts := strings.Split(e.pkg, "/")
+		b.WriteString(parts[len(parts)-1])
+		b.WriteString(".")
+	}
+	b.WriteString(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, e.name))
+	for _, arg := range e.args {
+		b.WriteString(sep)
+		b.WriteString(arg.formatArg(sep, qualified))
+	}
+	return b.String()
+}
+
+// formatArg is similar to format, but the first letter is upper-cased for concatenation (e.g. string -> String)
+func (e *typeExpr) formatArg(sep string, qualified bool) string {
+	s := e.format(sep, qualified)
+	if s == "" || e.kind != typeExprNamed || (qualified && e.pkg != "") {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
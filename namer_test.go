@@ -0,0 +1,92 @@
+package reflectopenapi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/pkg/jsonequal"
+)
+
+type PaginatedOutput[T any] struct {
+	Cursor string `json:"cursor"`
+	Items  T      `json:"items"`
+}
+
+type PaginatedItem struct {
+	Name string `json:"name"`
+}
+
+type Pair[K any, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+func TestSchemaNamer(t *testing.T) {
+	cases := []struct {
+		msg   string
+		namer reflectopenapi.SchemaNamer
+		input interface{}
+		want  string
+	}{
+		{msg: "default", namer: nil, input: PaginatedOutput[[]PaginatedItem]{}, want: "PaginatedOutput__github.com/podhmo/reflect-openapi_test.PaginatedItem"},
+		{msg: "camelcase", namer: reflectopenapi.CamelCaseSchemaNamer, input: PaginatedOutput[[]PaginatedItem]{}, want: "PaginatedOutputPaginatedItemList"},
+		{msg: "camelcase-builtin", namer: reflectopenapi.CamelCaseSchemaNamer, input: PaginatedOutput[map[string]*PaginatedItem]{}, want: "PaginatedOutputStringPaginatedItemMap"},
+		{msg: "underscore", namer: reflectopenapi.UnderscoreSchemaNamer, input: PaginatedOutput[[]PaginatedItem]{}, want: "PaginatedOutput_PaginatedItemList"},
+		{msg: "underscore-multi", namer: reflectopenapi.UnderscoreSchemaNamer, input: Pair[string, PaginatedOutput[PaginatedItem]]{}, want: "Pair_String_PaginatedOutput_PaginatedItem"},
+		{msg: "qualified", namer: reflectopenapi.QualifiedSchemaNamer, input: PaginatedOutput[[]PaginatedItem]{}, want: "reflect-openapi_test.PaginatedOutput_reflect-openapi_test.PaginatedItemList"},
+		{msg: "qualified-not-generics", namer: reflectopenapi.QualifiedSchemaNamer, input: PaginatedItem{}, want: "reflect-openapi_test.PaginatedItem"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.msg, func(t *testing.T) {
+			c := reflectopenapi.Config{
+				SkipValidation: true,
+				Extractor:      shapeCfg,
+				SchemaNamer:    tc.namer,
+			}
+
+			var ref *openapi3.SchemaRef
+			doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+				m.RegisterType(tc.input, func(r *openapi3.SchemaRef) { ref = r })
+			})
+			if err != nil {
+				t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+			}
+
+			if want, got := "#/components/schemas/"+tc.want, ref.Ref; want != got {
+				t.Errorf("$ref: want %q but got %q", want, got)
+			}
+			if _, ok := doc.Components.Schemas[tc.want]; !ok {
+				t.Errorf("components/schemas: %q is not found", tc.want)
+			}
+		})
+	}
+}
+
+func TestSchemaNamerWithRef(t *testing.T) {
+	c := reflectopenapi.Config{
+		SkipValidation: true,
+		Extractor:      shapeCfg,
+		SchemaNamer:    reflectopenapi.CamelCaseSchemaNamer,
+	}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterFunc(func() PaginatedOutput[[]PaginatedItem] { return PaginatedOutput[[]PaginatedItem]{} }).After(func(op *openapi3.Operation) {
+			m.Doc.AddOperation("/items", "GET", op)
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	want := `{"$ref": "#/components/schemas/PaginatedOutputPaginatedItemList"}`
+	got := doc.Paths.Find("/items").Get.Responses.Get(200).Value.Content.Get("application/json").Schema
+	if err := jsonequal.NoDiff(
+		jsonequal.FromString(want).Named("want"),
+		jsonequal.From(got).Named("got"),
+	); err != nil {
+		t.Errorf("%+v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package reflectopenapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	OpenAPIVersion30 = "3.0.0"
+	OpenAPIVersion31 = "3.1.0"
+
+	// JSONSchemaDialect31 is the default dialect of the schemas in OpenAPI 3.1.
+	JSONSchemaDialect31 = "https://spec.openapis.org/oas/3.1/dialect/base"
+)
+
+// AddWebhook adds the operation as the webhook. it is emitted as "webhooks" in OpenAPI 3.1, and "x-webhooks" in OpenAPI 3.0.
+func (m *Manager) AddWebhook(name string, method string, op *openapi3.Operation) {
+	if m.Doc.Extensions == nil {
+		m.Doc.Extensions = map[string]interface{}{}
+	}
+	webhooks, ok := m.Doc.Extensions["x-webhooks"].(openapi3.Paths)
+	if !ok {
+		webhooks = openapi3.Paths{}
+		m.Doc.Extensions["x-webhooks"] = webhooks
+	}
+	item, ok := webhooks[name]
+	if !ok {
+		item = &openapi3.PathItem{}
+		webhooks[name] = item
+	}
+	item.SetOperation(method, op)
+}
+
+// ConvertToV31 converts the OpenAPI 3.0 document to the OpenAPI 3.1 document.
+// e.g. nullable -> type: [T, "null"], exclusiveMinimum: true -> exclusiveMinimum: <number>, example -> examples, single-value enum -> const, x-webhooks -> webhooks
+// (jsonSchemaDialect is also set)
+func ConvertToV31(doc *openapi3.T) (map[string]interface{}, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal doc: %w", err)
+	}
+	var root map[string]interface{}
+	if err := json.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("unmarshal doc: %w", err)
+	}
+
+	root["openapi"] = OpenAPIVersion31
+	root["jsonSchemaDialect"] = JSONSchemaDialect31
+	if webhooks, ok := root["x-webhooks"]; ok {
+		delete(root, "x-webhooks")
+		root["webhooks"] = webhooks
+	}
+
+	if components, ok := root["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			for k, s := range schemas {
+				schemas[k] = convertSchemaToV31(s)
+			}
+		}
+	}
+	for k, v := range root {
+		if k == "components" {
+			if components, ok := v.(map[string]interface{}); ok {
+				for k, v := range components {
+					if k != "schemas" {
+						convertNodeToV31(v)
+					}
+				}
+			}
+			continue
+		}
+		convertNodeToV31(v)
+	}
+	return root, nil
+}
+
+// convertNodeToV31 walks the node (other than schema), and converts the schemas in it.
+func convertNodeToV31(node interface{}) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		for k, v := range node {
+			if k == "schema" {
+				node[k] = convertSchemaToV31(v)
+				continue
+			}
+			convertNodeToV31(v)
+		}
+	case []interface{}:
+		for _, v := range node {
+			convertNodeToV31(v)
+		}
+	}
+}
+
+func convertSchemaToV31(node interface{}) interface{} {
+	s, ok := node.(map[string]interface{})
+	if !ok {
+		return node
+	}
+
+	// sub schemas
+	for _, k := range []string{"items", "not"} {
+		if v, ok := s[k]; ok {
+			s[k] = convertSchemaToV31(v)
+		}
+	}
+	if v, ok := s["additionalProperties"].(map[string]interface{}); ok {
+		s["additionalProperties"] = convertSchemaToV31(v)
+	}
+	if props, ok := s["properties"].(map[string]interface{}); ok {
+		for k, v := range props {
+			props[k] = convertSchemaToV31(v)
+		}
+	}
+	for _, k := range []string{"allOf", "oneOf", "anyOf"} {
+		if refs, ok := s[k].([]interface{}); ok {
+			for i, v := range refs {
+				refs[i] = convertSchemaToV31(v)
+			}
+		}
+	}
+
+	// exclusiveMinimum: true, minimum: 0 -> exclusiveMinimum: 0
+	for _, pair := range [][2]string{{"exclusiveMinimum", "minimum"}, {"exclusiveMaximum", "maximum"}} {
+		if exclusive, ok := s[pair[0]].(bool); ok {
+			delete(s, pair[0])
+			if v, ok := s[pair[1]]; ok && exclusive {
+				delete(s, pair[1])
+				s[pair[0]] = v
+			}
+		}
+	}
+
+	// example: x -> examples: [x]
+	if v, ok := s["example"]; ok {
+		delete(s, "example")
+		s["examples"] = []interface{}{v}
+	}
+
+	// x-propertyNames -> propertyNames (see the map with non-string keys)
+	if v, ok := s["x-propertyNames"]; ok {
+		delete(s, "x-propertyNames")
+		s["propertyNames"] = v
+	}
+
+	// enum: [x] -> const: x (if nullable, enum: [x, null] is kept, const cannot accept null)
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) == 1 && s["nullable"] != true {
+		delete(s, "enum")
+		s["const"] = enum[0]
+	}
+
+	// nullable: true -> type: [T, "null"]
+	if nullable, ok := s["nullable"].(bool); ok {
+		delete(s, "nullable")
+		if nullable {
+			if typ, ok := s["type"].(string); ok {
+				s["type"] = []interface{}{typ, "null"}
+				if enum, ok := s["enum"].([]interface{}); ok {
+					s["enum"] = append(enum, nil)
+				}
+			} else {
+				return map[string]interface{}{"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}}}
+			}
+		}
+	}
+	return s
+}
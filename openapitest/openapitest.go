@@ -0,0 +1,190 @@
+// Package openapitest provides the test helpers that check the responses of the handlers conform to the operations of the doc
+// (built by reflectopenapi.Config.BuildDoc()), for catching the handlers that drift from their documented output types.
+package openapitest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/podhmo/reflect-openapi/pkg/docrouter"
+)
+
+// Violation is the mismatch between the response and the documented operation.
+type Violation struct {
+	Operation string // e.g. "GET /pets/{id}"
+	Status    int
+	Pointer   string // the JSON pointer of the response body (e.g. "/items/0/name"), empty if the violation is not about the body (or about the whole body)
+	Message   string
+}
+
+func (v Violation) String() string {
+	if v.Pointer == "" {
+		return fmt.Sprintf("%s (%d): %s", v.Operation, v.Status, v.Message)
+	}
+	return fmt.Sprintf("%s (%d): %s: %s", v.Operation, v.Status, v.Pointer, v.Message)
+}
+
+// Checker checks the responses with the doc.
+type Checker struct {
+	Router  routers.Router
+	Options *openapi3filter.Options // if nil, all schema errors of the body are reported
+}
+
+// New creates the checker, the doc is validated on building the router.
+// The servers of the doc are ignored and the requests are matched by the paths only.
+func New(doc *openapi3.T) (*Checker, error) {
+	router, err := docrouter.New(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{Router: router}, nil
+}
+
+// Check returns the violations of the response, the body of the response is restored after reading.
+//
+// The status code that is not documented is reported, and the success status code (2xx) is also reported if it is covered only by the default response
+// (the default response is used for the errors, e.g. Config.DefaultError).
+func (c *Checker) Check(req *http.Request, res *http.Response) []Violation {
+	route, pathParams, err := c.Router.FindRoute(req)
+	if err != nil {
+		return []Violation{{Operation: req.Method + " " + req.URL.Path, Status: res.StatusCode, Message: fmt.Sprintf("operation is not found: %v", err)}}
+	}
+	op := route.Method + " " + route.Path
+
+	responses := route.Operation.Responses
+	if ref := responseFor(responses, res.StatusCode); ref == nil {
+		if responses.Default() == nil {
+			return []Violation{{Operation: op, Status: res.StatusCode, Message: "status is not documented"}}
+		}
+		if 200 <= res.StatusCode && res.StatusCode < 300 {
+			return []Violation{{Operation: op, Status: res.StatusCode, Message: "success status is not documented (only the default response is found)"}}
+		}
+	} else if responses.Get(res.StatusCode) == nil {
+		// matched by the range (e.g. "2XX"), openapi3filter.ValidateResponse() looks up the exact status code only
+		copiedOp := *route.Operation
+		copiedOp.Responses = make(openapi3.Responses, len(responses)+1)
+		for k, v := range responses {
+			copiedOp.Responses[k] = v
+		}
+		copiedOp.Responses[strconv.Itoa(res.StatusCode)] = ref
+		copiedRoute := *route
+		copiedRoute.Operation = &copiedOp
+		route = &copiedRoute
+	}
+
+	var body []byte
+	if res.Body != nil {
+		body, err = io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return []Violation{{Operation: op, Status: res.StatusCode, Message: fmt.Sprintf("read body: %v", err)}}
+		}
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	options := c.Options
+	if options == nil {
+		options = &openapi3filter.Options{MultiError: true}
+	}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status:  res.StatusCode,
+		Header:  res.Header,
+		Options: options,
+	}
+	input.SetBodyBytes(body)
+
+	if err := openapi3filter.ValidateResponse(context.Background(), input); err != nil {
+		return violations(op, res.StatusCode, err, nil)
+	}
+	return nil
+}
+
+// responseFor returns the documented response of the status code, the exact status code is preferred to the range (e.g. "2XX").
+func responseFor(responses openapi3.Responses, status int) *openapi3.ResponseRef {
+	if ref := responses.Get(status); ref != nil {
+		return ref
+	}
+	return responses[fmt.Sprintf("%dXX", status/100)]
+}
+
+// violations flattens the error, the schema errors are reported with the JSON pointers.
+func violations(op string, status int, err error, dst []Violation) []Violation {
+	var multiErr openapi3.MultiError
+	if errors.As(err, &multiErr) {
+		for _, err := range multiErr {
+			dst = violations(op, status, err, dst)
+		}
+		return dst
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		var pointer string
+		if path := schemaErr.JSONPointer(); len(path) > 0 {
+			r := strings.NewReplacer("~", "~0", "/", "~1")
+			for _, p := range path {
+				pointer += "/" + r.Replace(p)
+			}
+		}
+		message := schemaErr.Reason
+		if schemaErr.SchemaField != "" {
+			message += " (" + schemaErr.SchemaField + ")"
+		}
+		return append(dst, Violation{Operation: op, Status: status, Pointer: pointer, Message: message})
+	}
+
+	var resErr *openapi3filter.ResponseError
+	if errors.As(err, &resErr) && resErr.Err == nil {
+		return append(dst, Violation{Operation: op, Status: status, Message: resErr.Reason})
+	}
+	return append(dst, Violation{Operation: op, Status: status, Message: err.Error()})
+}
+
+// AssertResponse reports the violations of the recorded response as the test errors.
+func (c *Checker) AssertResponse(t testing.TB, req *http.Request, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	for _, v := range c.Check(req, rec.Result()) {
+		t.Errorf("response does not conform to the doc: %s", v)
+	}
+}
+
+// Wrap returns the handler that asserts every response of the handler, the response is written to the original writer after the check.
+//
+// t.Errorf() is called from the goroutine of the server, so the server must be closed inside the test (e.g. defer ts.Close() for httptest.NewServer()).
+// If the server is still running after the test finishes, the violations are lost (or the test binary panics).
+func (c *Checker) Wrap(t testing.TB, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		res := rec.Result()
+		for _, v := range c.Check(req, res) {
+			t.Errorf("response does not conform to the doc: %s", v)
+		}
+
+		for k, vs := range res.Header {
+			w.Header()[k] = vs
+		}
+		w.WriteHeader(res.StatusCode)
+		if _, err := io.Copy(w, res.Body); err != nil {
+			t.Errorf("write response: %+v", err)
+		}
+	})
+}
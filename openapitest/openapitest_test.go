@@ -0,0 +1,209 @@
+package openapitest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+type Pet struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func ListPets() ([]Pet, error) { return nil, nil }
+
+type FindPetInput struct {
+	ID int64 `json:"id" in:"path"`
+}
+
+func FindPet(input FindPetInput) (*Pet, error) { return nil, nil }
+
+func newChecker(t *testing.T) *Checker {
+	t.Helper()
+	c := &reflectopenapi.Config{SkipValidation: true, DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Get("/pets", ListPets)
+		m.Get("/pets/{id}", FindPet)
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+	checker, err := New(doc)
+	if err != nil {
+		t.Fatalf("New(): unexpected error: %+v", err)
+	}
+	return checker
+}
+
+func respond(code int, contentType string, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(code)
+		io.WriteString(w, body)
+	})
+}
+
+func TestCheck(t *testing.T) {
+	checker := newChecker(t)
+
+	cases := []struct {
+		msg     string
+		path    string
+		handler http.Handler
+		want    []Violation
+	}{
+		{
+			msg:     "ok",
+			path:    "/pets/1",
+			handler: respond(200, "application/json", `{"id": 1, "name": "foo"}`),
+		},
+		{
+			msg:     "ok-error",
+			path:    "/pets/1",
+			handler: respond(404, "application/json", `{"code": 404, "message": "not found"}`),
+		},
+		{
+			msg:     "missing field",
+			path:    "/pets/1",
+			handler: respond(200, "application/json", `{"id": 1}`),
+			want:    []Violation{{Operation: "GET /pets/{id}", Status: 200, Pointer: "/name", Message: `property "name" is missing (required)`}},
+		},
+		{
+			msg:     "type mismatch in array",
+			path:    "/pets",
+			handler: respond(200, "application/json", `[{"id": 1, "name": "foo"}, {"id": "2", "name": "bar"}]`),
+			want:    []Violation{{Operation: "GET /pets", Status: 200, Pointer: "/1/id", Message: `value must be an integer (type)`}},
+		},
+		{
+			msg:     "invalid error response",
+			path:    "/pets/1",
+			handler: respond(500, "application/json", `{"message": "oops"}`),
+			want:    []Violation{{Operation: "GET /pets/{id}", Status: 500, Pointer: "/code", Message: `property "code" is missing (required)`}},
+		},
+		{
+			msg:     "undocumented success status",
+			path:    "/pets/1",
+			handler: respond(201, "application/json", `{"id": 1, "name": "foo"}`),
+			want:    []Violation{{Operation: "GET /pets/{id}", Status: 201, Message: "success status is not documented (only the default response is found)"}},
+		},
+		{
+			msg:     "unexpected content-type",
+			path:    "/pets/1",
+			handler: respond(200, "text/plain", `foo`),
+			want:    []Violation{{Operation: "GET /pets/{id}", Status: 200, Message: `response header Content-Type has unexpected value: "text/plain"`}},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			req := httptest.NewRequest("GET", c.path, nil)
+			rec := httptest.NewRecorder()
+			c.handler.ServeHTTP(rec, req)
+
+			got := checker.Check(req, rec.Result())
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckStatusRange(t *testing.T) {
+	c := &reflectopenapi.Config{SkipValidation: true, DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Get("/pets/{id}", FindPet).After(func(op *openapi3.Operation) {
+			op.Responses["2XX"] = op.Responses["200"]
+			delete(op.Responses, "200")
+		})
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+	checker, err := New(doc)
+	if err != nil {
+		t.Fatalf("New(): unexpected error: %+v", err)
+	}
+
+	cases := []struct {
+		msg     string
+		handler http.Handler
+		want    []Violation
+	}{
+		{
+			msg:     "ok",
+			handler: respond(201, "application/json", `{"id": 1, "name": "foo"}`),
+		},
+		{
+			msg:     "missing field", // validated with the 2XX response, not with the default response
+			handler: respond(200, "application/json", `{"id": 1}`),
+			want:    []Violation{{Operation: "GET /pets/{id}", Status: 200, Pointer: "/name", Message: `property "name" is missing (required)`}},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/pets/1", nil)
+			rec := httptest.NewRecorder()
+			c.handler.ServeHTTP(rec, req)
+
+			got := checker.Check(req, rec.Result())
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Check() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWrap(t *testing.T) {
+	checker := newChecker(t)
+
+	rt := &recordingT{TB: t}
+	ts := httptest.NewServer(checker.Wrap(rt, respond(200, "application/json", `{"id": 1}`)))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/pets/1")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+
+	// the response is passed through
+	if want, got := 200, res.StatusCode; want != got {
+		t.Errorf("status code, want %d, but got %d", want, got)
+	}
+	if diff := cmp.Diff(`{"id": 1}`, string(body)); diff != "" {
+		t.Errorf("response mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []string{`response does not conform to the doc: GET /pets/{id} (200): /name: property "name" is missing (required)`}
+	if diff := cmp.Diff(want, rt.errors); diff != "" {
+		t.Errorf("reported errors mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,24 @@
+// Package docrouter provides the router of the operations in the openapi doc, for the validation of the requests and the responses.
+package docrouter
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// New creates the router, the doc is validated on building the router.
+// The servers of the doc are ignored and the requests are matched by the paths only,
+// because the doc built by Config.BuildDoc() has the local development server (http://localhost:8888)
+// and the router rejects the requests to the other hosts (e.g. the ones of httptest.Server).
+func New(doc *openapi3.T) (routers.Router, error) {
+	copied := *doc
+	copied.Servers = nil
+	router, err := legacy.NewRouter(&copied)
+	if err != nil {
+		return nil, fmt.Errorf("new router: %w", err)
+	}
+	return router, nil
+}
@@ -0,0 +1,147 @@
+package reflectopenapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	shape "github.com/podhmo/reflect-shape"
+)
+
+// RequiredPolicy decides whether the field is required or not, for both the properties of the body and the parameters.
+type RequiredPolicy interface {
+	IsRequired(f RequiredField) bool
+}
+
+// RequiredField is the field to be decided by RequiredPolicy.
+type RequiredField struct {
+	Owner *shape.Shape // the struct that has the field
+	Name  string       // the name of the property or the parameter
+	In    string       // "" if the field is the property of the body, otherwise "query", "header" or "cookie"
+	Tag   reflect.StructTag
+	Shape *shape.Shape // Shape.Lv > 0 if the field is pointer
+
+	TagNameOption *TagNameOption
+}
+
+// explicitRequired returns the value of required tag (e.g. `required:"true"`), if exists.
+func (f RequiredField) explicitRequired() (bool, bool) {
+	v, ok := f.Tag.Lookup(f.TagNameOption.RequiredTag)
+	if !ok {
+		return false, false
+	}
+	required, _ := strconv.ParseBool(v)
+	return required, true
+}
+
+// hasOmitEmpty returns true if the name tag has omitempty (e.g. `json:"name,omitempty"`)
+func (f RequiredField) hasOmitEmpty() bool {
+	v, ok := f.Tag.Lookup(f.TagNameOption.NameTag)
+	if !ok {
+		return false
+	}
+	_, right, ok := strings.Cut(v, ",")
+	return ok && strings.Contains(right, "omitempty")
+}
+
+var (
+	_ RequiredPolicy = (*DefaultRequiredPolicy)(nil)
+	_ RequiredPolicy = (*OmitEmptyRequiredPolicy)(nil)
+	_ RequiredPolicy = (*PointerRequiredPolicy)(nil)
+	_ RequiredPolicy = (*TagRequiredPolicy)(nil)
+	_ RequiredPolicy = (*ValidatorRequiredPolicy)(nil)
+	_ RequiredPolicy = RequiredPolicyFunc(nil)
+)
+
+// DefaultRequiredPolicy is the historical behavior.
+// the property is required if it is not pointer and does not have omitempty, and the parameter is required only if it has `required:"true"`.
+// In both cases, the required tag takes precedence.
+type DefaultRequiredPolicy struct {
+	IsRequiredParameter func(reflect.StructTag) bool // if nil, the required tag is used (set as Config.IsRequiredCheckFunction)
+}
+
+func (p *DefaultRequiredPolicy) IsRequired(f RequiredField) bool {
+	if f.In != "" {
+		if p.IsRequiredParameter != nil {
+			return p.IsRequiredParameter(f.Tag)
+		}
+		required, _ := f.explicitRequired()
+		return required
+	}
+
+	if required, ok := f.explicitRequired(); ok {
+		return required
+	}
+	if v, ok := f.Tag.Lookup(f.TagNameOption.NameTag); ok && strings.Contains(v, ",") {
+		return !f.hasOmitEmpty()
+	}
+	return f.Shape.Lv == 0
+}
+
+// OmitEmptyRequiredPolicy treats the field as required if it does not have omitempty (e.g. `json:"name,omitempty"`).
+type OmitEmptyRequiredPolicy struct{}
+
+func (p *OmitEmptyRequiredPolicy) IsRequired(f RequiredField) bool {
+	if required, ok := f.explicitRequired(); ok {
+		return required
+	}
+	return !f.hasOmitEmpty()
+}
+
+// PointerRequiredPolicy treats the field as required if it is not pointer.
+type PointerRequiredPolicy struct{}
+
+func (p *PointerRequiredPolicy) IsRequired(f RequiredField) bool {
+	if required, ok := f.explicitRequired(); ok {
+		return required
+	}
+	return f.Shape.Lv == 0
+}
+
+// TagRequiredPolicy treats the field as required only if it has `required:"true"`.
+type TagRequiredPolicy struct{}
+
+func (p *TagRequiredPolicy) IsRequired(f RequiredField) bool {
+	required, _ := f.explicitRequired()
+	return required
+}
+
+// ValidatorRequiredPolicy treats the field as required if it has github.com/go-playground/validator's required (e.g. `validate:"required"`).
+type ValidatorRequiredPolicy struct {
+	TagName string // default is "validate"
+}
+
+func (p *ValidatorRequiredPolicy) IsRequired(f RequiredField) bool {
+	if required, ok := f.explicitRequired(); ok {
+		return required
+	}
+	// the tag is parsed in the same way as the constraints (the required after dive is for the items)
+	c := (&ValidatorConstraintExtractor{TagName: p.TagName}).ExtractConstraint(f.Tag)
+	return c != nil && c.Required
+}
+
+// RequiredPolicyFunc is the function version of RequiredPolicy.
+type RequiredPolicyFunc func(f RequiredField) bool
+
+func (fn RequiredPolicyFunc) IsRequired(f RequiredField) bool {
+	return fn(f)
+}
+
+// isRequiredField decides whether the field is required or not, with the policy for the owner type (if registered) or the default policy.
+func (t *Transformer) isRequiredField(owner *shape.Shape, f *shape.Field, name string, in string) bool {
+	policy := t.RequiredPolicy
+	if p, ok := t.requiredPolicyMap[owner.Type]; ok {
+		policy = p
+	}
+	if policy == nil {
+		policy = &DefaultRequiredPolicy{IsRequiredParameter: t.IsRequired}
+	}
+	return policy.IsRequired(RequiredField{
+		Owner:         owner,
+		Name:          name,
+		In:            in,
+		Tag:           f.Tag,
+		Shape:         f.Shape,
+		TagNameOption: &t.TagNameOption,
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"log"
 	"reflect"
-	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/podhmo/reflect-openapi/info"
@@ -51,7 +50,10 @@ type UseRefResolver struct {
 
 	DisableInputRef             bool
 	DisableOutputRef            bool
+	SplitInputOutput            bool  // if true, XxxInput (without readOnly properties) and XxxOutput (without writeOnly properties) are used (the inline properties are also visited, the $ref properties have their own variants)
 	AdditionalPropertiesAllowed *bool // set as Config.StrictSchema
+
+	variants map[*openapi3.Schema]map[Direction]*openapi3.Schema
 }
 
 var _ Resolver = (*UseRefResolver)(nil)
@@ -81,6 +83,13 @@ func (r *UseRefResolver) ResolveSchema(v *openapi3.Schema, s *shape.Shape, direc
 		name = s.Name
 	}
 
+	if r.SplitInputOutput {
+		if variant := r.variantSchema(v, name, s, direction); variant != nil {
+			v = variant
+			name = variant.Title
+		}
+	}
+
 	if (r.DisableInputRef && direction == DirectionInput) || r.DisableOutputRef && direction == DirectionOutput {
 		if v.Extensions == nil {
 			v.Extensions = map[string]interface{}{}
@@ -91,6 +100,118 @@ func (r *UseRefResolver) ResolveSchema(v *openapi3.Schema, s *shape.Shape, direc
 	return r.NameStore.GetOrCreatePair(v, name, s).Ref
 }
 
+// variantSchema returns the variant of the schema for the direction (e.g. UserInput without readOnly properties).
+// If the variant is not needed, returns nil.
+func (r *UseRefResolver) variantSchema(v *openapi3.Schema, name string, s *shape.Shape, direction Direction) *openapi3.Schema {
+	var suffix string
+	var drop func(*openapi3.Schema) bool
+	switch direction {
+	case DirectionInput:
+		suffix = "Input"
+		drop = func(s *openapi3.Schema) bool { return s.ReadOnly }
+	case DirectionOutput:
+		suffix = "Output"
+		drop = func(s *openapi3.Schema) bool { return s.WriteOnly }
+	default:
+		return nil
+	}
+
+	if variant, ok := r.variants[v][direction]; ok {
+		return variant
+	}
+
+	variant := r.dropProperties(v, drop)
+	if variant != nil {
+		variant.Title = r.variantName(name, s) + suffix
+		if v.Extensions != nil {
+			variant.Extensions = make(map[string]interface{}, len(v.Extensions))
+			for k, x := range v.Extensions {
+				variant.Extensions[k] = x
+			}
+		}
+	}
+
+	if r.variants == nil {
+		r.variants = map[*openapi3.Schema]map[Direction]*openapi3.Schema{}
+	}
+	if r.variants[v] == nil {
+		r.variants[v] = map[Direction]*openapi3.Schema{}
+	}
+	r.variants[v][direction] = variant
+	return variant
+}
+
+// dropProperties returns the copy of the schema without the dropped properties, the inline (not $ref) properties and items are also visited.
+// If nothing is dropped, returns nil.
+func (r *UseRefResolver) dropProperties(v *openapi3.Schema, drop func(*openapi3.Schema) bool) *openapi3.Schema {
+	var variant *openapi3.Schema
+	copyOnce := func() {
+		if variant != nil {
+			return
+		}
+		copied := *v
+		variant = &copied
+		variant.Properties = make(openapi3.Schemas, len(v.Properties))
+		for k, prop := range v.Properties {
+			variant.Properties[k] = prop
+		}
+	}
+
+	for k, prop := range v.Properties {
+		if prop.Value == nil {
+			continue
+		}
+		if drop(prop.Value) {
+			copyOnce()
+			delete(variant.Properties, k)
+		} else if prop.Ref == "" {
+			if sub := r.dropProperties(prop.Value, drop); sub != nil {
+				copyOnce()
+				variant.Properties[k] = &openapi3.SchemaRef{Value: sub}
+			}
+		}
+	}
+	if items := v.Items; items != nil && items.Ref == "" && items.Value != nil {
+		if sub := r.dropProperties(items.Value, drop); sub != nil {
+			copyOnce()
+			variant.Items = &openapi3.SchemaRef{Value: sub}
+		}
+	}
+	if variant == nil {
+		return nil
+	}
+
+	if len(v.Required) > 0 {
+		variant.Required = make([]string, 0, len(v.Required))
+		for _, k := range v.Required {
+			if _, ok := variant.Properties[k]; ok {
+				variant.Required = append(variant.Required, k)
+			}
+		}
+	}
+	if ns := r.NameStore; ns != nil && ns.info != nil {
+		if sinfo, ok := ns.info.SchemaInfo[v]; ok {
+			props := make([]string, 0, len(variant.Properties))
+			for _, k := range sinfo.OrderedProperties {
+				if _, ok := variant.Properties[k]; ok {
+					props = append(props, k)
+				}
+			}
+			ns.info.SchemaInfo[variant] = &info.SchemaInfo{ID: sinfo.ID, OrderedProperties: props}
+		}
+	}
+	return variant
+}
+
+// variantName returns the base name of the variant, the name is normalized before adding the suffix.
+// (the namers for generics drop the text after the type arguments, e.g. Paginated[[]main.User]Input)
+func (r *UseRefResolver) variantName(name string, s *shape.Shape) string {
+	if r.NameStore == nil || r.NameStore.Namer == nil {
+		return name
+	}
+	return r.NameStore.Namer(name, s)
+}
+
 func (r *UseRefResolver) ResolveParameter(v *openapi3.Parameter, s *shape.Shape) *openapi3.ParameterRef {
 	return &openapi3.ParameterRef{Value: v}
 }
@@ -112,6 +233,7 @@ type RefPair struct {
 type NameStore struct {
 	Prefix     string
 	OnConflict func(*RefPair, int)
+	Namer      SchemaNamer
 
 	pairMap map[string][]*RefPair
 	info    *info.Info
@@ -120,6 +242,7 @@ type NameStore struct {
 func NewNameStore() *NameStore {
 	ns := &NameStore{
 		Prefix:  "#/components/schemas/",
+		Namer:   DefaultSchemaNamer,
 		pairMap: map[string][]*RefPair{},
 	}
 	ns.OnConflict = ns.fixPairAsAddingSuffix
@@ -140,13 +263,8 @@ func (ns *NameStore) fixPairAsAddingSuffix(pair *RefPair, i int) {
 }
 
 func (ns *NameStore) GetOrCreatePair(v *openapi3.Schema, name string, shape *shape.Shape) *RefPair {
-	// normalize name
-	if strings.Contains(name, "[") {
-		name = strings.ReplaceAll(name, "[", "_")
-	}
-	if strings.Contains(name, "]") {
-		name = strings.ReplaceAll(name, "]", "")
-	}
+	// normalize name (e.g. for generics)
+	name = ns.Namer(name, shape)
 
 	pairs, existed := ns.pairMap[name]
 	if existed {
@@ -199,6 +317,7 @@ func (ns *NameStore) BindSchemas(doc *openapi3.T) {
 			if ns.info != nil {
 				if sinfo, ok := ns.info.SchemaInfo[pair.Def.Value]; ok {
 					sinfo.Name = pair.Name
+					sinfo.GoID = pair.Shape.FullName()
 				}
 			}
 		}
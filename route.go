@@ -0,0 +1,96 @@
+package reflectopenapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type route struct {
+	Method string
+	Path   string
+	Op     *openapi3.Operation
+}
+
+// Route registers the function as the operation of method and path. The path template variables (e.g. {userId}) are checked with the path parameters at commit time.
+func (m *Manager) Route(method string, path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	method = strings.ToUpper(method)
+	return m.RegisterFunc(fn, modifiers...).After(func(op *openapi3.Operation) {
+		m.Doc.AddOperation(path, method, op)
+		m.routes = append(m.routes, &route{Method: method, Path: path, Op: op})
+	})
+}
+
+func (m *Manager) Get(path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	return m.Route(http.MethodGet, path, fn, modifiers...)
+}
+func (m *Manager) Post(path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	return m.Route(http.MethodPost, path, fn, modifiers...)
+}
+func (m *Manager) Put(path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	return m.Route(http.MethodPut, path, fn, modifiers...)
+}
+func (m *Manager) Patch(path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	return m.Route(http.MethodPatch, path, fn, modifiers...)
+}
+func (m *Manager) Delete(path string, fn interface{}, modifiers ...func(*openapi3.Operation)) *RegisterFuncAction {
+	return m.Route(http.MethodDelete, path, fn, modifiers...)
+}
+
+var rPathVariable = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// pathVariables returns the variables of the path template (e.g. /users/{userId} -> [userId])
+func pathVariables(path string) []string {
+	matches := rPathVariable.FindAllStringSubmatch(path, -1)
+	vars := make([]string, 0, len(matches))
+	for _, m := range matches {
+		vars = append(vars, m[1])
+	}
+	return vars
+}
+
+// validateRoutes checks that every path template variable has the matching path parameter (and vice versa), for the operations registered by Route().
+func (m *Manager) validateRoutes() error {
+	var problems []string
+	for _, r := range m.routes {
+		where := fmt.Sprintf("%s %s (%s)", r.Method, r.Path, r.Op.OperationID)
+
+		params := map[string]*openapi3.Parameter{}
+		var candidates openapi3.Parameters
+		if item := m.Doc.Paths[r.Path]; item != nil {
+			candidates = append(candidates, item.Parameters...)
+		}
+		candidates = append(candidates, r.Op.Parameters...)
+		for _, ref := range candidates {
+			if ref.Value != nil && ref.Value.In == openapi3.ParameterInPath {
+				params[ref.Value.Name] = ref.Value
+			}
+		}
+
+		vars := pathVariables(r.Path)
+		seen := make(map[string]bool, len(vars))
+		for _, name := range vars {
+			seen[name] = true
+			p, ok := params[name]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("path parameter %q is not found in the input of %s", name, where))
+				continue
+			}
+			p.Required = true // path parameters are always required
+		}
+		for name := range params {
+			if !seen[name] {
+				problems = append(problems, fmt.Sprintf("path parameter %q is not found in the path template of %s", name, where))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("path template mismatch: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package reflectopenapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// RegisterSecurityScheme adds the security scheme to components/securitySchemes.
+// e.g. m.RegisterSecurityScheme("bearerAuth", openapi3.NewJWTSecurityScheme())
+func (m *Manager) RegisterSecurityScheme(name string, scheme *openapi3.SecurityScheme) {
+	if m.Doc.Components == nil {
+		m.Doc.Components = &openapi3.Components{}
+	}
+	if m.Doc.Components.SecuritySchemes == nil {
+		m.Doc.Components.SecuritySchemes = openapi3.SecuritySchemes{}
+	}
+	m.Doc.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: scheme}
+}
+
+// Security adds the security requirement to the operation, multiple calls mean the alternatives (OR).
+func (a *RegisterFuncAction) Security(name string, scopes ...string) *RegisterFuncAction {
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return a.After(func(op *openapi3.Operation) {
+		if op.Security == nil {
+			op.Security = openapi3.NewSecurityRequirements()
+		}
+		op.Security.With(openapi3.NewSecurityRequirement().Authenticate(name, scopes...))
+	})
+}
+
+// NoSecurity marks the operation as public, the global default requirement (Config.Security) is not applied.
+func (a *RegisterFuncAction) NoSecurity() *RegisterFuncAction {
+	return a.After(func(op *openapi3.Operation) {
+		op.Security = openapi3.NewSecurityRequirements()
+	})
+}
+
+// validateSecurityRequirements checks that every referenced security scheme exists in components/securitySchemes.
+func validateSecurityRequirements(doc *openapi3.T) error {
+	var schemes openapi3.SecuritySchemes
+	if doc.Components != nil {
+		schemes = doc.Components.SecuritySchemes
+	}
+
+	var missing []string
+	check := func(where string, requirements openapi3.SecurityRequirements) {
+		for _, req := range requirements {
+			for name := range req {
+				if _, ok := schemes[name]; !ok {
+					missing = append(missing, fmt.Sprintf("%q in %s", name, where))
+				}
+			}
+		}
+	}
+
+	check("global security", doc.Security)
+	for path, item := range doc.Paths {
+		for method, op := range item.Operations() {
+			if op.Security != nil {
+				check(fmt.Sprintf("%s %s (%s)", method, path, op.OperationID), *op.Security)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("security scheme is not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -57,17 +57,15 @@ func (s *MergeParamsInputSelector) SelectInput(fn *shape.Func) (*shape.Shape, st
 			continue
 		}
 
-		// todo: handling customization
-		required := p.Shape.Lv == 0
+		// the pointer argument of the scalar type is the query parameter (e.g. `json:"pretty,omitempty" in:"query"`).
+		// whether the argument is required or not is decided by Config.RequiredPolicy (no required tag is added here)
 		tag := fmt.Sprintf(`%s:%q`, s.transformer.TagNameOption.NameTag, p.Name)
-		if !required {
+		if p.Shape.Lv > 0 {
 			switch p.Shape.Kind {
 			case reflect.Chan, reflect.Interface, reflect.Slice, reflect.Array, reflect.Struct:
 			default:
-				tag += fmt.Sprintf(` %s:"query"`, s.transformer.TagNameOption.ParamTypeTag)
+				tag = fmt.Sprintf(`%s:%q %s:"query"`, s.transformer.TagNameOption.NameTag, p.Name+",omitempty", s.transformer.TagNameOption.ParamTypeTag)
 			}
-		} else {
-			tag += ` required:"true"`
 		}
 		if p.Doc != "" {
 			tag += fmt.Sprintf(` description:%q`, p.Doc)
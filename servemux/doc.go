@@ -0,0 +1,9 @@
+// Package servemux wraps http.ServeMux (Go 1.22+ patterns, e.g. "GET /users/{id}"),
+// registering the typed action function installs both the http handler (binder.Handler) and the openapi operation.
+//
+// The enhanced patterns of http.ServeMux are enabled only when the go directive of the main module is 1.22 or later
+// (or with GODEBUG=httpmuxgo121=0).
+//
+// The wildcard of the remaining segments (e.g. "GET /files/{path...}") is documented as the path parameter ("/files/{path}"),
+// it matches only one segment in the routers built from the doc (e.g. validation.New()).
+package servemux
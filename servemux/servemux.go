@@ -0,0 +1,109 @@
+//go:build go1.22
+
+package servemux
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/binder"
+)
+
+// Mux is the http.ServeMux that also registers the operation of the action function, if Manager is not nil.
+// The plain http handlers can be registered with the methods of the embedded http.ServeMux (e.g. mux.HandleFunc("GET /healthz", ...)).
+type Mux struct {
+	*http.ServeMux
+	Manager *reflectopenapi.Manager // if nil, only the handler is registered (e.g. at runtime)
+
+	Binder       *binder.Binder               // decodes the requests into the inputs, default is binder.New(nil)
+	ErrorCatalog *reflectopenapi.ErrorCatalog // maps the errors to the status codes, default is Manager.ErrorCatalog
+	// ErrorResponse builds the response body of the error (see binder.Handler.ErrorResponse).
+	ErrorResponse func(code int, err error) interface{}
+}
+
+func New(m *reflectopenapi.Manager) *Mux {
+	mux := &Mux{ServeMux: http.NewServeMux(), Manager: m, Binder: binder.New(nil)}
+	if m != nil {
+		mux.ErrorCatalog = m.ErrorCatalog
+	}
+	return mux
+}
+
+// Handle registers the handler built from the action function with the pattern (e.g. "GET /users/{id}"), and the action as the operation (e.g. GET /users/{id}).
+// The handler is binder.Handler, the input is bound from the request and the output is encoded as JSON.
+// If Manager is nil, the returned action is nil.
+//
+// The default input must be passed with HandleWithDefault(), RegisterFuncAction.DefaultInput() of the returned action changes only the doc.
+func Handle[I any, O any](mux *Mux, pattern string, action func(context.Context, I) (O, error)) *reflectopenapi.RegisterFuncAction {
+	return handle(mux, pattern, binder.NewHandler(mux.Binder, action))
+}
+
+// HandleWithDefault is Handle with the default input, the same value is used by the handler (binder.Handler.Default) and the doc (RegisterFuncAction.DefaultInput()).
+func HandleWithDefault[I any, O any](mux *Mux, pattern string, action func(context.Context, I) (O, error), input I) *reflectopenapi.RegisterFuncAction {
+	ac := handle(mux, pattern, binder.NewHandler(mux.Binder, action).WithDefault(input))
+	if ac == nil {
+		return nil
+	}
+	return ac.DefaultInput(input)
+}
+
+func handle[I any, O any](mux *Mux, pattern string, h *binder.Handler[I, O]) *reflectopenapi.RegisterFuncAction {
+	method, path, err := ParsePattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("servemux.Handle: %+v", err))
+	}
+
+	h.ErrorCatalog = mux.ErrorCatalog
+	h.ErrorResponse = mux.ErrorResponse
+	mux.ServeMux.Handle(pattern, h)
+
+	if mux.Manager == nil {
+		return nil
+	}
+	return mux.Manager.Route(method, path, h.Action)
+}
+
+// ParsePattern parses the pattern of http.ServeMux, and returns the method and the path of openapi.
+// e.g. "GET /users/{id}" -> ("GET", "/users/{id}"), "GET /files/{path...}" -> ("GET", "/files/{path}"), "GET /{$}" -> ("GET", "/")
+//
+// The method is required and must be uppercase (the methods of http.ServeMux are case-sensitive), and the host is not supported.
+//
+// The openapi path parameter matches only one segment, so the routers built from the doc (e.g. validation.New(), openapitest.New())
+// do not match the multiple segments of {path...} (e.g. "/files/a/b/c.txt").
+func ParsePattern(pattern string) (method string, path string, err error) {
+	method, rest, found := strings.Cut(strings.TrimSpace(pattern), " ")
+	if !found {
+		return "", "", fmt.Errorf("method is required in pattern %q (e.g. \"GET /users/{id}\")", pattern)
+	}
+	if method != strings.ToUpper(method) {
+		return "", "", fmt.Errorf("method must be uppercase in pattern %q (e.g. \"%s %s\")", pattern, strings.ToUpper(method), strings.TrimLeft(rest, " \t"))
+	}
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, "/") {
+		return "", "", fmt.Errorf("host is not supported in pattern %q", pattern)
+	}
+
+	segments := strings.Split(rest, "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+		name := seg[1 : len(seg)-1]
+		switch {
+		case name == "$": // {$} matches the end of the path
+			if i != len(segments)-1 {
+				return "", "", fmt.Errorf("{$} must be at the end of pattern %q", pattern)
+			}
+			segments[i] = ""
+		case strings.HasSuffix(name, "..."): // {path...} matches the remaining segments
+			if i != len(segments)-1 {
+				return "", "", fmt.Errorf("%s must be at the end of pattern %q", seg, pattern)
+			}
+			segments[i] = "{" + strings.TrimSuffix(name, "...") + "}"
+		}
+	}
+	return method, strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,167 @@
+//go:build go1.22
+
+package servemux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type GetUserInput struct {
+	ID string `json:"id" in:"path"`
+}
+
+var ErrNotFound = errors.New("not found")
+
+func GetUser(ctx context.Context, input GetUserInput) (*User, error) {
+	if input.ID == "0" {
+		return nil, ErrNotFound
+	}
+	return &User{ID: input.ID, Name: "foo"}, nil
+}
+
+type GetFileInput struct {
+	Path string `json:"path" in:"path"`
+}
+
+func GetFile(ctx context.Context, input GetFileInput) (string, error) { return input.Path, nil }
+
+type ListUsersInput struct {
+	Limit int `json:"limit" in:"query"`
+}
+
+type ListUsersOutput struct {
+	Limit int `json:"limit"`
+}
+
+func ListUsers(ctx context.Context, input ListUsersInput) (ListUsersOutput, error) {
+	return ListUsersOutput{Limit: input.Limit}, nil
+}
+
+func mount(mux *Mux) {
+	Handle(mux, "GET /users/{id}", GetUser)
+	Handle(mux, "GET /files/{path...}", GetFile)
+	HandleWithDefault(mux, "GET /users", ListUsers, ListUsersInput{Limit: 20})
+}
+
+func TestMux(t *testing.T) {
+	t.Run("doc", func(t *testing.T) {
+		c := &reflectopenapi.Config{SkipValidation: true}
+		doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+			mount(New(m))
+		})
+		if err != nil {
+			t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+		}
+
+		var got []string
+		for path, item := range doc.Paths {
+			for method, op := range item.Operations() {
+				got = append(got, method+" "+path+" "+op.OperationID)
+			}
+		}
+		sort.Strings(got)
+		want := []string{
+			"GET /files/{path} github.com/podhmo/reflect-openapi/servemux.GetFile",
+			"GET /users github.com/podhmo/reflect-openapi/servemux.ListUsers",
+			"GET /users/{id} github.com/podhmo/reflect-openapi/servemux.GetUser",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("operations mismatch (-want +got):\n%s", diff)
+		}
+
+		// the same default value as the handler
+		if want, got := 20, doc.Paths["/users"].Get.Parameters.GetByInAndName("query", "limit").Schema.Value.Default; want != got {
+			t.Errorf("default of limit, want %v, but got %v", want, got)
+		}
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		if !enhancedPatterns() {
+			t.Skip("the enhanced patterns of ServeMux are disabled, run with GODEBUG=httpmuxgo121=0")
+		}
+		mux := New(nil) // runtime only
+		mux.ErrorCatalog = reflectopenapi.NewErrorCatalog()
+		mux.ErrorCatalog.Register(ErrNotFound, 404, "not found")
+		mount(mux)
+
+		cases := []struct {
+			path string
+			code int
+			want string
+		}{
+			{path: "/users/1", code: 200, want: `{"id":"1","name":"foo"}` + "\n"},
+			{path: "/files/a/b/c.txt", code: 200, want: `"a/b/c.txt"` + "\n"},
+			{path: "/users/0", code: 404, want: `{"code":404,"message":"not found"}` + "\n"},
+			{path: "/users", code: 200, want: `{"limit":20}` + "\n"},
+			{path: "/users?limit=5", code: 200, want: `{"limit":5}` + "\n"},
+		}
+		for _, c := range cases {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest("GET", c.path, nil))
+			if want, got := c.code, rec.Code; want != got {
+				t.Errorf("GET %s: status code, want %d, but got %d", c.path, want, got)
+			}
+			if diff := cmp.Diff(c.want, rec.Body.String()); diff != "" {
+				t.Errorf("GET %s: response mismatch (-want +got):\n%s", c.path, diff)
+			}
+		}
+	})
+}
+
+// enhancedPatterns reports whether http.ServeMux uses the go1.22 patterns.
+// (they are disabled when the go version of the main module is older than 1.22)
+func enhancedPatterns() bool {
+	var got string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{x}", func(w http.ResponseWriter, r *http.Request) { got = r.PathValue("x") })
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	return got == "ok"
+}
+
+func TestParsePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		method  string
+		path    string
+		hasErr  bool
+	}{
+		{pattern: "GET /users/{id}", method: "GET", path: "/users/{id}"},
+		{pattern: "POST /users", method: "POST", path: "/users"},
+		{pattern: "post /users", hasErr: true}, // ServeMux matches only the literal "post" method
+		{pattern: "GET /files/{path...}", method: "GET", path: "/files/{path}"},
+		{pattern: "GET /{$}", method: "GET", path: "/"},
+		{pattern: "GET /users/{$}", method: "GET", path: "/users/"},
+		{pattern: "/users", hasErr: true},
+		{pattern: "GET example.com/users", hasErr: true},
+		{pattern: "GET /{path...}/x", hasErr: true},
+	}
+	for _, c := range cases {
+		method, path, err := ParsePattern(c.pattern)
+		if c.hasErr {
+			if err == nil {
+				t.Errorf("ParsePattern(%q): must be error, but nil", c.pattern)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePattern(%q): unexpected error: %+v", c.pattern, err)
+			continue
+		}
+		if c.method != method || c.path != path {
+			t.Errorf("ParsePattern(%q): want (%q, %q), but got (%q, %q)", c.pattern, c.method, c.path, method, path)
+		}
+	}
+}
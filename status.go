@@ -0,0 +1,76 @@
+package reflectopenapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	shape "github.com/podhmo/reflect-shape"
+)
+
+// StatusCoder is the interface for the output type that declares its status code (e.g. 201 for Created[T]).
+// The method is called with the zero value.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+var rstatusCoderType = reflect.TypeOf(func(StatusCoder) {}).In(0)
+
+// Created is the output wrapper for 201 Created response. (e.g. func CreateUser(ctx context.Context, input CreateUserInput) (Created[User], error))
+type Created[T any] struct {
+	Body T `in:"body"`
+}
+
+func (Created[T]) StatusCode() int { return http.StatusCreated }
+
+func (v Created[T]) MarshalJSON() ([]byte, error) { return json.Marshal(v.Body) }
+
+// Accepted is the output wrapper for 202 Accepted response.
+type Accepted[T any] struct {
+	Body T `in:"body"`
+}
+
+func (Accepted[T]) StatusCode() int { return http.StatusAccepted }
+
+func (v Accepted[T]) MarshalJSON() ([]byte, error) { return json.Marshal(v.Body) }
+
+// statusCode returns the status code declared by the output type, with StatusCode() method or the marker field (e.g. `_ struct{} `status:"201"`).
+func (t *Transformer) statusCode(s *shape.Shape) (int, bool) {
+	rt := s.Type
+	if reflect.PointerTo(rt).Implements(rstatusCoderType) {
+		return reflect.New(rt).Interface().(StatusCoder).StatusCode(), true
+	}
+
+	if rt.Kind() != reflect.Struct || t.TagNameOption.StatusTag == "" {
+		return 0, false
+	}
+	for i := 0; i < rt.NumField(); i++ {
+		v, ok := rt.Field(i).Tag.Lookup(t.TagNameOption.StatusTag)
+		if !ok {
+			continue
+		}
+		code, err := strconv.Atoi(v)
+		if err != nil {
+			log.Printf("[WARN]  invalid status tag: %q in %s.%s", v, rt, rt.Field(i).Name)
+			continue
+		}
+		return code, true
+	}
+	return 0, false
+}
+
+// responseBody returns the shape of the response body, the field with `in:"body"` is used if exists (e.g. Created[T].Body).
+func (t *Transformer) responseBody(s *shape.Shape) *shape.Shape {
+	if s.Kind != reflect.Struct {
+		return s
+	}
+	for _, f := range s.Struct().Fields() {
+		if v, ok := f.Tag.Lookup(t.TagNameOption.ParamTypeTag); ok && strings.ToLower(v) == "body" {
+			return f.Shape
+		}
+	}
+	return s
+}
@@ -0,0 +1,469 @@
+package reflectopenapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const OpenAPIVersion20 = "2.0"
+
+// V2Issue is the construct that cannot be represented in Swagger 2.0 (reported by ConvertToV2).
+type V2Issue struct {
+	Path    string // json pointer of the construct in the OpenAPI 3.0 document (e.g. #/paths/~1pets/post/requestBody)
+	Message string
+}
+
+func (i V2Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// BuildDocV2 builds the doc as OpenAPI 3.0 and converts it to Swagger 2.0 with ConvertToV2(). The issues of the conversion are logged.
+func (c *Config) BuildDocV2(ctx context.Context, use func(m *Manager)) (*openapi2.T, error) {
+	doc, err := c.BuildDoc(ctx, use)
+	if err != nil {
+		return nil, err
+	}
+	doc2, issues, err := ConvertToV2(doc)
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		log.Printf("[WARN]  swagger2.0: %s", issue)
+	}
+	return doc2, nil
+}
+
+// ConvertToV2 converts the OpenAPI 3.0 document to the Swagger 2.0 document.
+// e.g. components/schemas -> definitions, requestBody -> in: body or in: formData parameters, servers -> host, basePath and schemes.
+// The constructs that cannot be represented (e.g. oneOf, cookie parameters, callbacks) are returned as issues, and dropped if it is invalid in Swagger 2.0.
+// The passed doc is not modified.
+func ConvertToV2(doc *openapi3.T) (*openapi2.T, []V2Issue, error) {
+	// deep copy, openapi2conv modifies the schemas in place
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal doc: %w", err)
+	}
+	var copied openapi3.T
+	if err := json.Unmarshal(b, &copied); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal doc: %w", err)
+	}
+	if copied.Info == nil {
+		copied.Info = &openapi3.Info{}
+	}
+	if copied.Components == nil {
+		copied.Components = &openapi3.Components{}
+	}
+
+	c := &v2Converter{doc: &copied}
+	c.prepare()
+
+	doc2, err := openapi2conv.FromV3(c.doc)
+	if err != nil {
+		return nil, c.issues, fmt.Errorf("convert to swagger2.0: %w", err)
+	}
+	doc2.Swagger = OpenAPIVersion20
+	c.fix(doc2)
+	return doc2, c.issues, nil
+}
+
+type v2Converter struct {
+	doc    *openapi3.T
+	issues []V2Issue
+}
+
+func (c *v2Converter) report(path string, format string, args ...interface{}) {
+	c.issues = append(c.issues, V2Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// prepare drops or rewrites the constructs of the OpenAPI 3.0 document that openapi2conv cannot handle.
+func (c *v2Converter) prepare() {
+	doc := c.doc
+
+	if _, ok := doc.Extensions["x-webhooks"]; ok {
+		delete(doc.Extensions, "x-webhooks")
+		c.report("#/x-webhooks", "webhooks are not supported, dropped")
+	}
+
+	if len(doc.Servers) > 1 {
+		c.report("#/servers", "multiple servers are not supported, only the first one (%s) is used as host and basePath", doc.Servers[0].URL)
+	}
+	for i, server := range doc.Servers {
+		if len(server.Variables) > 0 {
+			c.report(fmt.Sprintf("#/servers/%d", i), "server variables are not supported")
+		}
+		if _, err := url.Parse(server.URL); err != nil {
+			c.report(fmt.Sprintf("#/servers/%d", i), "server url %q is not parsable, skipped", server.URL)
+		}
+	}
+
+	components := doc.Components
+	for _, name := range sortedKeys(components.Schemas) {
+		c.walkSchema("#/components/schemas/"+escapePointer(name), components.Schemas[name])
+	}
+	if len(components.Headers) > 0 {
+		c.report("#/components/headers", "reusable headers are not supported")
+	}
+	if len(components.Examples) > 0 {
+		c.report("#/components/examples", "reusable examples are not supported")
+	}
+	if len(components.Links) > 0 {
+		c.report("#/components/links", "links are not supported")
+	}
+	if len(components.Callbacks) > 0 {
+		c.report("#/components/callbacks", "callbacks are not supported")
+	}
+	for _, name := range sortedKeys(components.SecuritySchemes) {
+		ref := components.SecuritySchemes[name]
+		path := "#/components/securitySchemes/" + escapePointer(name)
+		if ref.Value == nil {
+			continue
+		}
+		switch ref.Value.Type {
+		case "apiKey", "oauth2":
+			if ref.Value.In == "cookie" {
+				delete(components.SecuritySchemes, name)
+				c.report(path, "apiKey in cookie is not supported, dropped")
+			}
+		case "http":
+			if ref.Value.Scheme != "basic" {
+				c.report(path, "http %s scheme is not supported, emitted as apiKey in Authorization header", ref.Value.Scheme)
+			}
+		default:
+			delete(components.SecuritySchemes, name)
+			c.report(path, "%s security scheme is not supported, dropped", ref.Value.Type)
+		}
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		prefix := "#/paths/" + escapePointer(path)
+		item.Parameters = c.prepareParameters(prefix+"/parameters", item.Parameters)
+
+		ops := item.Operations()
+		for _, method := range sortedKeys(ops) {
+			op := ops[method]
+			prefix := prefix + "/" + strings.ToLower(method)
+			op.Parameters = c.prepareParameters(prefix+"/parameters", op.Parameters)
+			if len(op.Callbacks) > 0 {
+				op.Callbacks = nil
+				c.report(prefix+"/callbacks", "callbacks are not supported, dropped")
+			}
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				c.prepareRequestBody(prefix+"/requestBody", op.RequestBody.Value)
+			}
+			for _, code := range sortedKeys(op.Responses) {
+				ref := op.Responses[code]
+				if ref.Value == nil {
+					continue
+				}
+				prefix := prefix + "/responses/" + code
+				if len(ref.Value.Links) > 0 {
+					c.report(prefix+"/links", "links are not supported, dropped")
+				}
+				if len(ref.Value.Content) > 1 {
+					c.report(prefix+"/content", "multiple content types are not supported, the schema of %s is used", responseContentType(ref.Value.Content))
+				}
+				for _, mime := range sortedKeys(ref.Value.Content) {
+					if mt := ref.Value.Content[mime]; mt != nil {
+						c.walkSchema(prefix+"/content/"+escapePointer(mime)+"/schema", mt.Schema)
+					}
+				}
+				for _, name := range sortedKeys(ref.Value.Headers) {
+					if h := ref.Value.Headers[name]; h != nil && h.Value != nil {
+						c.walkSchema(prefix+"/headers/"+escapePointer(name)+"/schema", h.Value.Schema)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (c *v2Converter) prepareParameters(prefix string, params openapi3.Parameters) openapi3.Parameters {
+	if len(params) == 0 {
+		return params
+	}
+	r := make(openapi3.Parameters, 0, len(params))
+	for i, ref := range params {
+		path := fmt.Sprintf("%s/%d", prefix, i)
+		if ref.Value != nil {
+			if ref.Value.In == openapi3.ParameterInCookie {
+				c.report(path, "cookie parameter %q is not supported, dropped", ref.Value.Name)
+				continue
+			}
+			if ref.Value.Style == openapi3.SerializationDeepObject {
+				c.report(path, "deepObject style of parameter %q is not supported", ref.Value.Name)
+			}
+			c.walkSchema(path+"/schema", ref.Value.Schema)
+		}
+		r = append(r, ref)
+	}
+	return r
+}
+
+func (c *v2Converter) prepareRequestBody(prefix string, body *openapi3.RequestBody) {
+	// only one content type is allowed as the body parameter (or the formData parameters)
+	if len(body.Content) > 1 {
+		mime := requestContentType(body.Content)
+		c.report(prefix+"/content", "multiple content types are not supported, only %s is used", mime)
+		body.Content = openapi3.Content{mime: body.Content[mime]}
+	}
+	for mime, mt := range body.Content {
+		if mt == nil || mt.Schema == nil {
+			continue
+		}
+		path := prefix + "/content/" + escapePointer(mime) + "/schema"
+		if mime != "application/x-www-form-urlencoded" && mime != "multipart/form-data" {
+			c.walkSchema(path, mt.Schema)
+			continue
+		}
+
+		// formData parameters are built from the properties of the inline schema
+		schema := c.resolveSchema(mt.Schema)
+		if schema == nil {
+			c.report(path, "the schema of %s is not found", mt.Schema.Ref)
+			continue
+		}
+		copied := *schema
+		copied.Properties = make(openapi3.Schemas, len(schema.Properties))
+		for _, name := range sortedKeys(schema.Properties) {
+			prop := schema.Properties[name]
+			if v := c.resolveSchema(prop); v != nil {
+				prop = &openapi3.SchemaRef{Value: v}
+				if v.Type == "object" {
+					c.report(path+"/properties/"+escapePointer(name), "object in formData is not supported")
+				}
+				if len(v.Required) == 0 && contains(schema.Required, name) {
+					// openapi2conv checks the required of the property itself
+					v2 := *v
+					v2.Required = []string{name}
+					prop = &openapi3.SchemaRef{Value: &v2}
+				}
+			}
+			copied.Properties[name] = prop
+		}
+		mt.Schema = &openapi3.SchemaRef{Value: &copied}
+	}
+}
+
+func (c *v2Converter) resolveSchema(ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref == nil {
+		return nil
+	}
+	if ref.Ref == "" {
+		return ref.Value
+	}
+	name := strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+	if def, ok := c.doc.Components.Schemas[name]; ok {
+		return def.Value
+	}
+	return nil
+}
+
+// walkSchema reports the constructs of the inline schema that cannot be represented.
+func (c *v2Converter) walkSchema(path string, ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Ref != "" || ref.Value == nil {
+		return
+	}
+	s := ref.Value
+	if len(s.OneOf) > 0 {
+		c.report(path, "oneOf is not supported")
+	}
+	if len(s.AnyOf) > 0 {
+		c.report(path, "anyOf is not supported")
+	}
+	if s.Not != nil {
+		c.report(path, "not is not supported")
+	}
+	if s.WriteOnly {
+		c.report(path, "writeOnly is not supported")
+	}
+	if s.Nullable && s.Extensions == nil {
+		s.Extensions = map[string]interface{}{} // emitted as x-nullable by openapi2conv
+	}
+
+	for _, name := range sortedKeys(s.Properties) {
+		c.walkSchema(path+"/properties/"+escapePointer(name), s.Properties[name])
+	}
+	c.walkSchema(path+"/items", s.Items)
+	c.walkSchema(path+"/additionalProperties", s.AdditionalProperties.Schema)
+	for k, refs := range map[string]openapi3.SchemaRefs{"allOf": s.AllOf, "oneOf": s.OneOf, "anyOf": s.AnyOf} {
+		for i, ref := range refs {
+			c.walkSchema(fmt.Sprintf("%s/%s/%d", path, k, i), ref)
+		}
+	}
+}
+
+// fix modifies the converted Swagger 2.0 document, for the points that openapi2conv does not handle.
+func (c *v2Converter) fix(doc2 *openapi2.T) {
+	for path, item := range c.doc.Paths {
+		item2 := doc2.Paths[path]
+		if item == nil || item2 == nil {
+			continue
+		}
+		c.inlineParameters(item2.Parameters)
+
+		for method, op := range item.Operations() {
+			op2 := item2.GetOperation(method)
+			if op2 == nil {
+				continue
+			}
+			c.inlineParameters(op2.Parameters)
+
+			produces := map[string]bool{}
+			for code, ref := range op.Responses {
+				res2 := op2.Responses[code]
+				if ref.Value == nil || res2 == nil {
+					continue
+				}
+				for mime := range ref.Value.Content {
+					produces[mime] = true
+				}
+				if res2.Description == "" {
+					// description is required in Swagger 2.0 (and omitted if empty)
+					res2.Description = responseDescription(code)
+				}
+				for _, h := range res2.Headers {
+					c.inlineParameter(&h.Parameter)
+				}
+
+				// openapi2conv uses only application/json
+				mime := responseContentType(ref.Value.Content)
+				mt := ref.Value.Content[mime]
+				if mt == nil {
+					continue
+				}
+				if res2.Schema == nil && mt.Schema != nil {
+					res2.Schema, _ = openapi2conv.FromV3SchemaRef(mt.Schema, c.doc.Components)
+				}
+				if example := mediaTypeExample(mt); example != nil {
+					res2.Examples = map[string]interface{}{mime: example}
+				}
+			}
+			if len(produces) > 0 {
+				op2.Produces = make([]string, 0, len(produces))
+				for mime := range produces {
+					op2.Produces = append(op2.Produces, mime)
+				}
+				sort.Strings(op2.Produces)
+			}
+		}
+	}
+}
+
+func (c *v2Converter) inlineParameters(params openapi2.Parameters) {
+	for _, p := range params {
+		if p.In != "body" {
+			c.inlineParameter(p)
+		}
+	}
+}
+
+// inlineParameter resolves the $ref of the non-body parameter (e.g. the enum type), because only the body parameter can have the schema.
+func (c *v2Converter) inlineParameter(p *openapi2.Parameter) {
+	if p.Items != nil && p.Items.Ref != "" {
+		if v := c.resolveSchema(&openapi3.SchemaRef{Ref: openapi2conv.ToV3Ref(p.Items.Ref)}); v != nil {
+			p.Items = &openapi3.SchemaRef{Value: v}
+		}
+	}
+	if p.Schema == nil || p.Schema.Ref == "" {
+		return
+	}
+	schema := c.resolveSchema(&openapi3.SchemaRef{Ref: openapi2conv.ToV3Ref(p.Schema.Ref)})
+	if schema == nil {
+		return
+	}
+	p.Schema = nil
+	p.Type = schema.Type
+	p.Format = schema.Format
+	p.Enum = schema.Enum
+	p.Minimum = schema.Min
+	p.Maximum = schema.Max
+	p.ExclusiveMin = schema.ExclusiveMin
+	p.ExclusiveMax = schema.ExclusiveMax
+	p.MinLength = schema.MinLength
+	p.MaxLength = schema.MaxLength
+	p.Pattern = schema.Pattern
+	p.Default = schema.Default
+	p.Items = schema.Items
+	p.MinItems = schema.MinItems
+	p.MaxItems = schema.MaxItems
+	p.UniqueItems = schema.UniqueItems
+	p.MultipleOf = schema.MultipleOf
+	if p.Description == "" {
+		p.Description = schema.Description
+	}
+}
+
+func responseDescription(code string) string {
+	if n, err := strconv.Atoi(code); err == nil {
+		if text := http.StatusText(n); text != "" {
+			return text
+		}
+	}
+	return code
+}
+
+func mediaTypeExample(mt *openapi3.MediaType) interface{} {
+	if mt.Example != nil {
+		return mt.Example
+	}
+	for _, name := range sortedKeys(mt.Examples) {
+		if ref := mt.Examples[name]; ref != nil && ref.Value != nil {
+			return ref.Value.Value
+		}
+	}
+	return nil
+}
+
+// requestContentType returns the content type used as the body parameter, application/json is preferred.
+func requestContentType(content openapi3.Content) string {
+	for _, mime := range []string{"application/json", "multipart/form-data", "application/x-www-form-urlencoded"} {
+		if _, ok := content[mime]; ok {
+			return mime
+		}
+	}
+	keys := sortedKeys(content)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// responseContentType returns the content type used as the schema of the response, application/json is preferred.
+func responseContentType(content openapi3.Content) string {
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	keys := sortedKeys(content)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
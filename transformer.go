@@ -1,9 +1,12 @@
 package reflectopenapi
 
 import (
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"go/token"
 	"log"
+	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
@@ -15,6 +18,11 @@ import (
 	shape "github.com/podhmo/reflect-shape"
 )
 
+var (
+	rtextMarshalerType = reflect.TypeOf(func(encoding.TextMarshaler) {}).In(0)
+	rjsonMarshalerType = reflect.TypeOf(func(json.Marshaler) {}).In(0)
+)
+
 type Transformer struct {
 	Resolver
 	Selector Selector
@@ -29,7 +37,24 @@ type Transformer struct {
 	defaultValues map[int]reflect.Value
 
 	interceptFuncMap map[reflect.Type]func(*shape.Shape) *openapi3.Schema
-	IsRequired       func(reflect.StructTag) bool
+	unionMap         map[reflect.Type]*unionDef
+	IsRequired       func(reflect.StructTag) bool // for parameters, used by DefaultRequiredPolicy
+
+	RequiredPolicy    RequiredPolicy // if nil, DefaultRequiredPolicy is used
+	requiredPolicyMap map[reflect.Type]RequiredPolicy
+
+	EmbeddedAsAllOf     bool                                // if true, embedded structs are emitted as allOf composition, instead of flattening
+	EnumLookup          *EnumLookup                         // for detecting enum from the typed constants, if nil, enum is not detected
+	ConstraintExtractor ConstraintExtractor                 // for validation tags (e.g. `validate:"required,min=1"`), if nil, constraints are not extracted
+	JSONMarshalerHook   func(*shape.Shape) *openapi3.Schema // for json.Marshaler types, if returns nil, the type is transformed as usual
+
+	discriminatorRefs map[*openapi3.Discriminator]map[string]*openapi3.SchemaRef // for fixing mapping after name conflict
+
+	requestContentTypes map[int]string // content type of the request body per function (set by RegisterFuncAction.RequestContentType())
+
+	visiting       map[int]bool // for detecting recursive types
+	recursive      map[int]bool
+	recursiveStore *NameStore // for recursive types, if the resolver does not have NameStore (e.g. NoRefResolver)
 
 	Fset           *token.FileSet
 	GoPositionFunc func(fset *token.FileSet, fn *shape.Func) string
@@ -49,6 +74,13 @@ func (t *Transformer) Builtin() *Transformer {
 			return v
 		})
 	}
+	for _, rt := range fileTypes { // e.g. *multipart.FileHeader, io.Reader
+		t.RegisterInterception(rt, func(s *shape.Shape) *openapi3.Schema {
+			v := openapi3.NewStringSchema()
+			v.Format = "binary"
+			return v
+		})
+	}
 	{
 		var z time.Time
 		t.RegisterInterception(reflect.ValueOf(z).Type(), func(s *shape.Shape) *openapi3.Schema {
@@ -79,10 +111,21 @@ func (t *Transformer) isRequired(tag reflect.StructTag) bool {
 	return v
 }
 
+func (t *Transformer) extractConstraint(tag reflect.StructTag) *Constraint {
+	if t.ConstraintExtractor == nil {
+		return nil
+	}
+	return t.ConstraintExtractor.ExtractConstraint(tag)
+}
+
 func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *Schema | *Response
 	id := s.Number
 	if retval, ok := t.cache[id]; ok {
 		t.CacheHit++
+		if t.visiting[id] && !t.recursive[id] {
+			log.Printf("[INFO]  recursive type is found: %v, it is always used via $ref (components/schemas) to avoid cyclic schema", s.Type)
+			t.recursive[id] = true
+		}
 		return retval
 	}
 
@@ -93,6 +136,34 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		return retval
 	}
 
+	// e.g. for the type with MarshalJSON as {"type": "string"} (by JSONMarshalerHook), the wire format is prior to the structure of go's type
+	isJSONMarshaler := s.Kind != reflect.Interface && implements(s.Type, rjsonMarshalerType)
+	if isJSONMarshaler && t.JSONMarshalerHook != nil {
+		if retval := t.JSONMarshalerHook(s); retval != nil {
+			copied := *retval // not cached, the fields write their default and description into it
+			return &copied
+		}
+	}
+
+	// e.g. for type SortOrder string with const SortOrderAsc SortOrder = "asc", the enum is detected from the typed constants
+	if t.EnumLookup != nil && s.Type.PkgPath() != "" {
+		switch s.Kind {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if retval := t.transformEnum(s); retval != nil {
+				t.cache[id] = retval
+				return retval
+			}
+		}
+	}
+
+	// e.g. for uuid.UUID as {"type": "string"}, the type with its own MarshalJSON is transformed as usual
+	if s.Kind != reflect.Interface && !isJSONMarshaler && implements(s.Type, rtextMarshalerType) {
+		return openapi3.NewStringSchema()
+	}
+
 	switch s.Kind {
 	case reflect.Bool:
 		return openapi3.NewBoolSchema()
@@ -112,6 +183,8 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 			schema.Description = doc
 		}
 		t.cache[id] = schema
+		t.visiting[id] = true
+		defer delete(t.visiting, id)
 
 		// add default value
 		if rv := s.DefaultValue; schema.Default == nil && rv.IsValid() && !rv.IsZero() && s.Name != "" {
@@ -132,7 +205,22 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		if doc := ob.Doc(); doc != "" {
 			schema.Description = doc
 		}
-		flattenFields := flattenFieldsWithValue(ob.Fields(), rob)
+		fields := ob.Fields()
+		var bases []*openapi3.SchemaRef // for allOf composition
+		{
+			rest := make(shape.FieldList, 0, len(fields))
+			for _, f := range fields {
+				if !t.isAllOfEmbedded(f) {
+					rest = append(rest, f)
+					continue
+				}
+				if subschema, ok := t.Transform(f.Shape).(*openapi3.Schema); ok {
+					bases = append(bases, t.ResolveSchema(subschema, f.Shape, DirectionInternal))
+				}
+			}
+			fields = rest
+		}
+		flattenFields := flattenFieldsWithValue(fields, rob)
 		propNames := make([]string, 0, len(flattenFields))
 		for _, f := range flattenFields {
 			oaType, ok := f.Tag.Lookup(t.TagNameOption.ParamTypeTag)
@@ -145,17 +233,18 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 			}
 
 			name := f.Name
-			defaultRequired := f.Shape.Lv == 0
 			hasOmitEmpty := false
 			if v, ok := f.Tag.Lookup(t.TagNameOption.NameTag); ok {
 				if left, right, ok := strings.Cut(v, ","); ok {
 					name = left
 					hasOmitEmpty = strings.Contains(right, "omitempty")
-					defaultRequired = !hasOmitEmpty
 				} else {
 					name = v
 				}
 			}
+			if ok && isFormParamType(oaType) {
+				name = t.formFieldName(f.Field)
+			}
 			if name == "-" {
 				continue
 			}
@@ -163,8 +252,13 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 				// skip if json tag is not found and unexported field
 				continue
 			}
+			required := t.isRequiredField(s, f.Field, name, "")
 
-			switch f.Shape.Kind {
+			kind := f.Shape.Kind
+			if isFileType(f.Shape.Type) {
+				kind = reflect.String // e.g. *multipart.FileHeader is not object
+			}
+			switch kind {
 			case reflect.Struct:
 				subschema, ok := t.Transform(f.Shape).(*openapi3.Schema) // xxx
 				if !ok {
@@ -177,13 +271,15 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 					subschema.Nullable = true
 					log.Printf("[INFO] has not omitempty, changes to nullable=true (from %q struct {... %s %s%s `%s`;} )", ob.Shape.Type, f.Name, strings.Repeat("*", f.Shape.Lv), f.Shape.Type, f.Tag)
 				}
-				if v, ok := f.Tag.Lookup(t.TagNameOption.RequiredTag); ok {
-					if ok, _ := strconv.ParseBool(v); ok {
-						schema.Required = append(schema.Required, name)
-					}
-				} else if defaultRequired {
+				if required {
 					schema.Required = append(schema.Required, name)
 				}
+				if c := t.extractConstraint(f.Tag); c != nil && c.Required && !contains(schema.Required, name) {
+					schema.Required = append(schema.Required, name)
+				}
+				if v, ok := f.Tag.Lookup(t.TagNameOption.AccessModeTag); ok {
+					schema.Properties[name] = t.applyAccessMode(schema.Properties[name], v)
+				}
 
 			case reflect.Func, reflect.Chan:
 				continue
@@ -196,15 +292,36 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 				propNames = append(propNames, name)
 				schema.Properties[name] = ref
 
-				if v, ok := f.Tag.Lookup(t.TagNameOption.RequiredTag); ok {
+				if required {
+					schema.Required = append(schema.Required, name)
+				}
+				if v, ok := f.Tag.Lookup(t.TagNameOption.RequiredTag); ok && f.Shape.Lv > 0 {
 					if ok, _ := strconv.ParseBool(v); ok {
+						subschema.Nullable = true
+					}
+				}
+				if c := t.extractConstraint(f.Tag); c != nil {
+					if c.Required && !contains(schema.Required, name) {
 						schema.Required = append(schema.Required, name)
-						if f.Shape.Lv > 0 {
-							subschema.Nullable = true
+					}
+					ref = t.applyConstraint(ref, c)
+					schema.Properties[name] = ref
+				}
+				if v, ok := f.Tag.Lookup(t.TagNameOption.AccessModeTag); ok {
+					ref = t.applyAccessMode(ref, v)
+					schema.Properties[name] = ref
+				}
+
+				// the schema of the enum is shared by the fields, so the field-level values are written into the copy
+				if ref.Value != nil && len(ref.Value.Enum) > 0 && ref.Value == t.cache[f.Shape.Number] {
+					copied := *ref.Value
+					if t.info != nil {
+						if sinfo, ok := t.info.SchemaInfo[ref.Value]; ok {
+							t.info.SchemaInfo[&copied] = sinfo
 						}
 					}
-				} else if defaultRequired {
-					schema.Required = append(schema.Required, name)
+					ref = &openapi3.SchemaRef{Ref: ref.Ref, Value: &copied}
+					schema.Properties[name] = ref
 				}
 
 				// default
@@ -240,7 +357,7 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		}
 
 		// too add-hoc?
-		if len(schema.Properties) == 0 && ob.Fields().Len() > 0 {
+		if len(bases) == 0 && len(schema.Properties) == 0 && ob.Fields().Len() > 0 {
 			ok := true
 			schema.AdditionalProperties.Has = &ok
 			schema.Description = "<unclear definition>"
@@ -248,6 +365,22 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		if t.info != nil {
 			t.info.SchemaInfo[schema] = &info.SchemaInfo{ID: id, OrderedProperties: propNames}
 		}
+
+		// allOf: [{$ref: Base}, {own properties}]
+		if len(bases) > 0 {
+			schema.AllOf = bases
+			if len(schema.Properties) > 0 {
+				own := openapi3.NewObjectSchema()
+				own.Properties = schema.Properties
+				own.Required = schema.Required
+				schema.AllOf = append(schema.AllOf, openapi3.NewSchemaRef("", own))
+				if t.info != nil {
+					t.info.SchemaInfo[own] = &info.SchemaInfo{ID: id, OrderedProperties: propNames}
+				}
+			}
+			schema.Properties = nil
+			schema.Required = nil
+		}
 		return schema
 	case reflect.Func:
 		// return *openapi.Operation
@@ -280,10 +413,20 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		// parameters
 		if inob, description := t.Selector.SelectInput(fn); inob != nil {
 			schema := t.Transform(inob).(*openapi3.Schema) // xxx
-			if len(schema.Properties) > 0 {
-				// todo: required,content,description
-				body := openapi3.NewRequestBody().
-					WithJSONSchemaRef(t.ResolveSchema(schema, inob, DirectionInput))
+			// allOf: e.g. the embedded struct with EmbeddedAsAllOf
+			if len(schema.Properties) > 0 || len(schema.AllOf) > 0 {
+				// todo: required,description
+				body := openapi3.NewRequestBody()
+				switch mime := t.requestContentType(s, inob, schema); mime {
+				case ContentTypeJSON:
+					body.WithJSONSchemaRef(t.ResolveSchema(schema, inob, DirectionInput))
+				default:
+					mediaType := openapi3.NewMediaType().WithSchemaRef(t.ResolveSchema(schema, inob, DirectionInput))
+					if mime == ContentTypeMultipartForm || mime == ContentTypeURLEncodedForm {
+						mediaType.Encoding = t.formEncoding(inob, schema, mime)
+					}
+					body.WithContent(openapi3.Content{mime: mediaType})
+				}
 				body.Description = description
 				op.RequestBody = t.ResolveRequestBody(body, inob)
 			}
@@ -318,7 +461,7 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 
 					var p *openapi3.Parameter
 					switch strings.ToLower(paramType) {
-					case "json":
+					case "json", "form", "formdata": // in request body
 						continue
 					case "path":
 						if v, ok := f.Tag.Lookup("path"); ok {
@@ -329,44 +472,23 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 						if v, ok := f.Tag.Lookup("query"); ok {
 							name = v
 						}
-						p = openapi3.NewQueryParameter(name).WithRequired(t.IsRequired(f.Tag))
+						p = openapi3.NewQueryParameter(name).WithRequired(t.isRequiredField(inob.Shape, f.Field, name, "query"))
 					case "header":
 						if v, ok := f.Tag.Lookup("header"); ok {
 							name = v
 						}
-						p = openapi3.NewHeaderParameter(name).WithRequired(t.IsRequired(f.Tag))
+						p = openapi3.NewHeaderParameter(name).WithRequired(t.isRequiredField(inob.Shape, f.Field, name, "header"))
 					case "cookie":
 						if v, ok := f.Tag.Lookup("cookie"); ok {
 							name = v
 						}
-						p = openapi3.NewCookieParameter(name).WithRequired(t.IsRequired(f.Tag))
+						p = openapi3.NewCookieParameter(name).WithRequired(t.isRequiredField(inob.Shape, f.Field, name, "cookie"))
 					default:
-						log.Printf("[WARN]  invalid openapiTag: %q in %s.%s, suppored values are [path, query, header, cookie]", inob.Shape.Type, f.Name, f.Tag.Get(t.TagNameOption.ParamTypeTag))
+						log.Printf("[WARN]  invalid openapiTag: %q in %s.%s, suppored values are [path, query, header, cookie, form]", inob.Shape.Type, f.Name, f.Tag.Get(t.TagNameOption.ParamTypeTag))
 						continue
 					}
 
-					schema := t.Transform(f.Shape).(*openapi3.Schema)
-					// override: e.g. `openapi-override:"{'minimum': 0}"`
-					if v, ok := f.Tag.Lookup(t.TagNameOption.OverrideTag); ok {
-						b := []byte(strings.ReplaceAll(strings.ReplaceAll(v, `\`, `\\`), "'", "\""))
-						if _, err := marshmallow.Unmarshal(b, schema); err != nil { // enable cache?
-							log.Printf("[WARN]  openapi-override: unmarshal json is failed: %q", v)
-						}
-					}
-
-					p.Schema = t.ResolveSchema(schema, f.Shape, DirectionParameter)
-					p.Description = f.Doc
-					if v, ok := f.Tag.Lookup(t.TagNameOption.DescriptionTag); ok {
-						p.Description = v
-					}
-					if f.value.IsValid() {
-						if f.Shape.Kind == reflect.Bool {
-							p.Schema.Value.Default = f.value.Interface()
-						} else if !shape.IsZeroRecursive(f.value.Type(), f.value) {
-							p.Schema.Value.Default = f.value.Interface()
-						}
-					}
-
+					t.fillParameter(p, f)
 					params = append(params, t.ResolveParameter(p, f.Shape))
 				}
 				if len(params) > 0 {
@@ -377,11 +499,31 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 
 		// responses
 		if outob, description := t.Selector.SelectOutput(fn); outob != nil {
-			schema := t.Transform(outob).(*openapi3.Schema) // xxx
-			ref := t.ResolveSchema(schema, outob, DirectionOutput)
+			code := 200
+			if n, ok := t.statusCode(outob); ok { // e.g. 201 for Created[T]
+				code = n
+			}
+			bodyob := t.responseBody(outob)
+			schema := t.Transform(bodyob).(*openapi3.Schema) // xxx
 			doc := description
-			response := openapi3.NewResponse().WithDescription(doc).WithJSONSchemaRef(ref)
-			op.Responses["200"] = t.ResolveResponse(response, outob)
+			response := openapi3.NewResponse().WithDescription(doc)
+			headers := t.responseHeaders(outob)
+			if bodyob != outob {
+				for k, h := range t.responseHeaders(bodyob) {
+					if headers == nil {
+						headers = openapi3.Headers{}
+					}
+					headers[k] = h
+				}
+			}
+			if code != http.StatusNoContent && (bodyob != outob || len(headers) == 0 || len(schema.Properties) > 0 || len(schema.AllOf) > 0 || schema.Type != "object") {
+				ref := t.ResolveSchema(schema, bodyob, DirectionOutput)
+				response.WithJSONSchemaRef(ref)
+			}
+			if len(headers) > 0 {
+				response.Headers = headers
+			}
+			op.Responses[strconv.Itoa(code)] = t.ResolveResponse(response, outob)
 		}
 		return op
 	case reflect.Slice, reflect.Array:
@@ -397,7 +539,7 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		} else {
 			rob = newInnerValue(s.Type)
 		}
-		innerShape := t.Extractor.Extract(rob.Interface())
+		innerShape := t.extractValue(rob)
 
 		inner, ok := t.Transform(innerShape).(*openapi3.Schema)
 		if !ok {
@@ -406,19 +548,45 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 		schema.Items = t.ResolveSchema(inner, innerShape, DirectionInternal)
 		return schema
 	case reflect.Map:
-		if s.Type.Key().Kind() != reflect.String {
-			panic(fmt.Sprintf("not supported type %v, support only map[string, <V>]", s))
+		// same as encoding/json, the key is string, encoding.TextMarshaler, or integer
+		keyType := s.Type.Key()
+		keyPattern := ""
+		switch keyType.Kind() {
+		case reflect.String:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			keyPattern = "^-?[0-9]+$"
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			keyPattern = "^[0-9]+$"
+		default:
+			if !keyType.Implements(rtextMarshalerType) {
+				panic(fmt.Sprintf("not supported type %v, support only map[string, <V>], map[<integer>, <V>] or map[<encoding.TextMarshaler>, <V>]", s))
+			}
+		}
+		if keyType.Implements(rtextMarshalerType) { // encoding.TextMarshaler is prior to integer
+			keyPattern = ""
 		}
+
 		schema := openapi3.NewSchema()
 		t.cache[id] = schema
+		if keyType.Kind() != reflect.String {
+			schema.Extensions = map[string]interface{}{"x-go-key-type": keyType.String()}
+			if keyPattern != "" {
+				schema.Extensions["x-propertyNames"] = map[string]interface{}{"pattern": keyPattern} // propertyNames is not supported in openapi 3.0
+			}
+		}
 
 		rob := newInnerValue(s.Type)
-		innerShape := t.Extractor.Extract(rob.Interface())
+		innerShape := t.extractValue(rob)
 
 		inner := t.Transform(innerShape).(*openapi3.Schema)
 		schema.AdditionalProperties.Schema = t.ResolveSchema(inner, innerShape, DirectionInternal)
 		return schema
 	case reflect.Interface:
+		// e.g. for sealed interface as {"oneOf": [{"$ref": "#/components/schemas/A"}, {"$ref": "#/components/schemas/B"}]}
+		if u, ok := t.unionMap[s.Type]; ok {
+			return t.transformUnion(s, u)
+		}
+
 		iface := s.Interface()
 
 		schema := openapi3.NewObjectSchema()
@@ -439,6 +607,137 @@ func (t *Transformer) Transform(s *shape.Shape) interface{} { // *Operation | *S
 	}
 }
 
+type unionDef struct {
+	impls         []interface{}
+	anyOf         bool   // if true, use anyOf instead of oneOf
+	discriminator string // property name of discriminator
+}
+
+func (t *Transformer) transformUnion(s *shape.Shape, u *unionDef) *openapi3.Schema {
+	schema := openapi3.NewSchema()
+	schema.Title = s.Name
+	if doc := s.Named().Doc(); doc != "" {
+		schema.Description = doc
+	}
+	t.cache[s.Number] = schema
+	t.visiting[s.Number] = true
+	defer delete(t.visiting, s.Number)
+
+	refs := make(openapi3.SchemaRefs, 0, len(u.impls))
+	mapping := map[string]*openapi3.SchemaRef{}
+	for _, impl := range u.impls {
+		// the schema is built from the zero value, the value of impl is used only for the discriminator value (not as default)
+		implShape := t.Extractor.Extract(reflect.Zero(reflect.TypeOf(impl)).Interface())
+		subschema, ok := t.Transform(implShape).(*openapi3.Schema)
+		if !ok {
+			log.Printf("[WARN]  union %v: %v is not supported as member, ignored", s.Type, implShape.Type)
+			continue
+		}
+		ref := t.ResolveSchema(subschema, implShape, DirectionInternal)
+		refs = append(refs, ref)
+		if u.discriminator != "" && ref.Ref != "" { // mapping is only available with $ref
+			mapping[t.discriminatorValue(implShape, reflect.ValueOf(impl), u.discriminator)] = ref
+		}
+	}
+	if u.anyOf {
+		schema.AnyOf = refs
+	} else {
+		schema.OneOf = refs
+	}
+
+	if u.discriminator != "" {
+		schema.Discriminator = &openapi3.Discriminator{PropertyName: u.discriminator}
+		if len(mapping) > 0 {
+			schema.Discriminator.Mapping = make(map[string]string, len(mapping))
+			for k, ref := range mapping {
+				schema.Discriminator.Mapping[k] = ref.Ref
+			}
+			t.discriminatorRefs[schema.Discriminator] = mapping
+		}
+	}
+	if t.info != nil {
+		t.info.SchemaInfo[schema] = &info.SchemaInfo{ID: s.Number}
+	}
+	return schema
+}
+
+// discriminatorValue returns the value of discriminator, lookup order is struct tag, field value, and type name.
+func (t *Transformer) discriminatorValue(s *shape.Shape, rob reflect.Value, propertyName string) string {
+	if s.Kind != reflect.Struct {
+		return s.Name
+	}
+
+	for rob.IsValid() && rob.Kind() == reflect.Pointer {
+		if rob.IsNil() {
+			rob = reflect.Value{}
+			break
+		}
+		rob = rob.Elem()
+	}
+	if !rob.IsValid() {
+		rob = newValue(s.Type)
+	}
+	for _, f := range flattenFieldsWithValue(s.Struct().Fields(), rob) {
+		name := f.Name
+		if v, ok := f.Tag.Lookup(t.TagNameOption.NameTag); ok {
+			name, _, _ = strings.Cut(v, ",")
+		}
+		if name != propertyName {
+			continue
+		}
+
+		if v, ok := f.Tag.Lookup(t.TagNameOption.DiscriminatorTag); ok && v != "" {
+			return v
+		}
+		if f.value.IsValid() && f.value.Kind() == reflect.String && f.value.String() != "" {
+			return f.value.String()
+		}
+		break
+	}
+	return s.Name
+}
+
+// ResolveSchema is similar to Resolver.ResolveSchema, but the recursive type is always resolved as $ref, even if the resolver is NoRefResolver (or DisableInputRef/DisableOutputRef is true).
+func (t *Transformer) ResolveSchema(v *openapi3.Schema, s *shape.Shape, direction Direction) *openapi3.SchemaRef {
+	if isFileType(s.Type) { // e.g. *multipart.FileHeader is always {"type": "string", "format": "binary"}, without $ref
+		return &openapi3.SchemaRef{Value: v}
+	}
+	ref := t.Resolver.ResolveSchema(v, s, direction)
+	if ref.Ref != "" || !t.recursive[s.Number] {
+		return ref
+	}
+
+	name := v.Title
+	if name == "" {
+		name = s.Name
+	}
+	if r, ok := t.Resolver.(*UseRefResolver); ok && r.NameStore != nil {
+		return r.NameStore.GetOrCreatePair(v, name, s).Ref
+	}
+	if t.recursiveStore == nil {
+		t.recursiveStore = NewNameStore()
+		t.recursiveStore.info = t.info
+	}
+	return t.recursiveStore.GetOrCreatePair(v, name, s).Ref
+}
+
+// bindRecursiveSchemas binds the schemas of recursive types to components/schemas (only if the resolver is not Binder)
+func (t *Transformer) bindRecursiveSchemas(doc *openapi3.T) {
+	if t.recursiveStore == nil {
+		return
+	}
+	t.recursiveStore.BindSchemas(doc)
+}
+
+// fixDiscriminatorMapping updates the mapping of discriminators, because the name of schema may be changed by name conflict.
+func (t *Transformer) fixDiscriminatorMapping() {
+	for d, mapping := range t.discriminatorRefs {
+		for k, ref := range mapping {
+			d.Mapping[k] = ref.Ref
+		}
+	}
+}
+
 func notImplementedYet(s *shape.Shape) interface{} {
 	if FORCE {
 		log.Printf("[INFO]  not implemented yet for %+v", s)
@@ -447,6 +746,18 @@ func notImplementedYet(s *shape.Shape) interface{} {
 	panic(fmt.Sprintf("not implemented yet for %v\nIf you want to run forcibly, execute with FORCE=1", s))
 }
 
+func implements(rt reflect.Type, iface reflect.Type) bool {
+	return rt.Implements(iface) || reflect.PointerTo(rt).Implements(iface)
+}
+
+// extractValue extracts the shape of the value, handling nil interface value (e.g. the element of []interface{})
+func (t *Transformer) extractValue(rv reflect.Value) *shape.Shape {
+	if rv.Kind() == reflect.Interface && rv.IsNil() {
+		return t.Extractor.Extract(reflect.New(rv.Type()).Interface())
+	}
+	return t.Extractor.Extract(rv.Interface())
+}
+
 // return not zero inner value from map or slice
 func newInnerValue(rt reflect.Type) reflect.Value {
 	return newValue(rt.Elem())
@@ -470,6 +781,126 @@ type fieldWithValue struct {
 	value reflect.Value
 }
 
+// applyAccessMode sets readOnly/writeOnly to the copied schema, by the value of struct tag (e.g. `openapi:"readonly"`)
+func (t *Transformer) applyAccessMode(ref *openapi3.SchemaRef, mode string) *openapi3.SchemaRef {
+	var readOnly, writeOnly bool
+	for _, x := range strings.Split(mode, ",") {
+		switch strings.ToLower(strings.TrimSpace(x)) {
+		case "readonly":
+			readOnly = true
+		case "writeonly":
+			writeOnly = true
+		}
+	}
+	if !readOnly && !writeOnly {
+		return ref
+	}
+
+	var schema *openapi3.Schema
+	if ref.Ref != "" || ref.Value == nil {
+		schema = &openapi3.Schema{AllOf: openapi3.SchemaRefs{ref}} // the siblings of $ref are ignored
+	} else {
+		copied := *ref.Value
+		schema = &copied
+		if t.info != nil {
+			if sinfo, ok := t.info.SchemaInfo[ref.Value]; ok {
+				t.info.SchemaInfo[schema] = sinfo
+			}
+		}
+	}
+	schema.ReadOnly = readOnly
+	schema.WriteOnly = writeOnly
+	return &openapi3.SchemaRef{Value: schema}
+}
+
+// isAllOfEmbedded returns true if the field is composed with allOf, instead of flattening.
+// (e.g. Config.EmbeddedAsAllOf is true, or struct { Base `embedded:"allof"` })
+// only the anonymous field is composed, because encoding/json nests the named field under its name.
+func (t *Transformer) isAllOfEmbedded(f *shape.Field) bool {
+	if !f.Anonymous || f.Shape.Kind != reflect.Struct || f.Shape.Name == "" {
+		return false
+	}
+	if v, ok := f.Tag.Lookup("embedded"); ok {
+		if strings.EqualFold(v, "allof") {
+			return true
+		}
+		if ok, err := strconv.ParseBool(v); err == nil && !ok {
+			return false
+		}
+	}
+	return f.Anonymous && t.EmbeddedAsAllOf
+}
+
+// fillParameter fills the schema, description and default value of the parameter (or the response header) from the field.
+func (t *Transformer) fillParameter(p *openapi3.Parameter, f fieldWithValue) {
+	schema := t.Transform(f.Shape).(*openapi3.Schema)
+	// override: e.g. `openapi-override:"{'minimum': 0}"`
+	if v, ok := f.Tag.Lookup(t.TagNameOption.OverrideTag); ok {
+		b := []byte(strings.ReplaceAll(strings.ReplaceAll(v, `\`, `\\`), "'", "\""))
+		if _, err := marshmallow.Unmarshal(b, schema); err != nil { // enable cache?
+			log.Printf("[WARN]  openapi-override: unmarshal json is failed: %q", v)
+		}
+	}
+
+	p.Schema = t.ResolveSchema(schema, f.Shape, DirectionParameter)
+	if c := t.extractConstraint(f.Tag); c != nil {
+		if c.Required {
+			p.Required = true
+		}
+		p.Schema = t.applyConstraint(p.Schema, c)
+	}
+	p.Description = f.Doc
+	if v, ok := f.Tag.Lookup(t.TagNameOption.DescriptionTag); ok {
+		p.Description = v
+	}
+	if f.value.IsValid() {
+		if f.Shape.Kind == reflect.Bool {
+			p.Schema.Value.Default = f.value.Interface()
+		} else if !shape.IsZeroRecursive(f.value.Type(), f.value) {
+			p.Schema.Value.Default = f.value.Interface()
+		}
+	}
+}
+
+// responseHeaders returns the response headers declared on the output struct (e.g. `in:"header" header:"Location"`), these fields are not included in the body.
+func (t *Transformer) responseHeaders(outob *shape.Shape) openapi3.Headers {
+	if outob.Kind != reflect.Struct {
+		return nil
+	}
+	rob := outob.DefaultValue
+	if rv, ok := t.defaultValues[outob.Number]; ok {
+		rob = rv
+	} else if !rob.IsValid() {
+		rob = newValue(outob.Type)
+	}
+
+	var headers openapi3.Headers
+	for _, f := range flattenFieldsWithValue(outob.Struct().Fields(), rob) {
+		paramType, ok := f.Tag.Lookup(t.TagNameOption.ParamTypeTag)
+		if !ok || strings.ToLower(paramType) != "header" {
+			continue
+		}
+
+		name := f.Name
+		if v, ok := f.Tag.Lookup(t.TagNameOption.NameTag); ok {
+			if left, _, _ := strings.Cut(v, ","); left != "" && left != "-" {
+				name = left
+			}
+		}
+		if v, ok := f.Tag.Lookup("header"); ok {
+			name = v
+		}
+
+		p := new(openapi3.Parameter).WithRequired(t.isRequiredField(outob, f.Field, name, "header"))
+		t.fillParameter(p, f)
+		if headers == nil {
+			headers = openapi3.Headers{}
+		}
+		headers[name] = &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: *p}}
+	}
+	return headers
+}
+
 func flattenFieldsWithValue(fields shape.FieldList, rv reflect.Value) []fieldWithValue {
 	// warning: may include reflect.invalid (e.g. *string with nil)
 	r := make([]fieldWithValue, 0, fields.Len())
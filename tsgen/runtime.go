@@ -0,0 +1,48 @@
+package tsgen
+
+// runtimeCode is the code of the fetch client, shared by the functions of the operations.
+// (ResponseError extends globalThis.Error, because components/schemas often has "Error")
+const runtimeCode = `export interface Client {
+  baseURL: string;
+  /** if not set, globalThis.fetch is used */
+  fetch?: typeof fetch;
+  /** added to all requests (e.g. Authorization) */
+  headers?: Record<string, string>;
+}
+
+/** the error of the non-2xx response, the body is the parsed JSON (or the text) */
+export class ResponseError<T = unknown> extends globalThis.Error {
+  readonly status: number;
+  readonly body: T;
+
+  constructor(status: number, body: T) {
+    super(` + "`unexpected response: ${status}`" + `);
+    this.name = "ResponseError";
+    this.status = status;
+    this.body = body;
+  }
+}
+
+async function send(client: Client, method: string, path: string, query: URLSearchParams, headers: Record<string, string>, body: BodyInit | undefined, init?: RequestInit): Promise<Response> {
+  const qs = query.toString();
+  const url = client.baseURL.replace(/\/$/, "") + path + (qs ? "?" + qs : "");
+  return (client.fetch ?? fetch)(url, {
+    ...init,
+    method,
+    headers: { ...client.headers, ...headers, ...(init?.headers as Record<string, string> | undefined) },
+    body,
+  });
+}
+
+async function responseError<T>(res: Response): Promise<ResponseError<T>> {
+  const text = await res.text();
+  let body: unknown = text;
+  try {
+    body = JSON.parse(text);
+  } catch {
+    // not JSON
+  }
+  return new ResponseError<T>(res.status, body as T);
+}
+
+`
@@ -0,0 +1,286 @@
+// Package tsgen generates the typescript types and the fetch client from the doc (built by reflectopenapi.Config.BuildDoc()).
+//
+// The interfaces keep the names of components/schemas, the order of the go struct fields (info.SchemaInfo.OrderedProperties) and the doc comments (as TSDoc).
+package tsgen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
+	"github.com/podhmo/reflect-openapi/walknode"
+)
+
+type Config struct {
+	DisableClient bool // if true, only the types are generated
+}
+
+// Generate writes the typescript code.
+func Generate(w io.Writer, doc *openapi3.T, info *info.Info, config *Config) error {
+	if config == nil {
+		config = &Config{}
+	}
+	g := &generator{Config: config, doc: doc, info: info}
+
+	g.resolveNames()
+	out := new(bytes.Buffer)
+	fmt.Fprintln(out, "// Code generated by github.com/podhmo/reflect-openapi/tsgen; DO NOT EDIT.")
+	fmt.Fprintln(out)
+	g.writeComponents(out)
+	if !g.DisableClient {
+		io.WriteString(out, runtimeCode)
+		g.writeOperations(out)
+	}
+
+	if _, err := w.Write(bytes.TrimRight(out.Bytes(), "\n")); err != nil {
+		return fmt.Errorf("write generated code: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("write generated code: %w", err)
+	}
+	return nil
+}
+
+type generator struct {
+	*Config
+	doc  *openapi3.T
+	info *info.Info
+
+	names          map[string]bool   // the declared names
+	componentNames map[string]string // the name in components/schemas -> the typescript name
+}
+
+type operation struct {
+	Name   string // e.g. FindPets
+	Method string
+	Path   string
+	Op     *openapi3.Operation
+}
+
+func (g *generator) operations() []operation {
+	var ops []operation
+	seen := map[string]int{}
+	walknode.PathItem(g.doc, func(pathItem *openapi3.PathItem, path string) {
+		walknode.Operation(pathItem, func(op *openapi3.Operation, method string) {
+			name := op.OperationID
+			if i := strings.LastIndexAny(name, "./"); i >= 0 {
+				name = name[i+1:]
+			}
+			if name == "" {
+				name = strings.ToLower(method) + "_" + path
+			}
+			name = tsName(name)
+			name = strings.ToUpper(name[:1]) + name[1:]
+			seen[name]++
+			if n := seen[name]; n > 1 {
+				name = fmt.Sprintf("%s%d", name, n)
+			}
+			ops = append(ops, operation{Name: name, Method: method, Path: path, Op: op})
+		})
+	})
+	return ops
+}
+
+func (g *generator) writeOperations(w *bytes.Buffer) {
+	for _, op := range g.operations() {
+		g.writeOperation(w, op, oasutil.UniqueName(g.names, op.Name+"Request"))
+	}
+}
+
+func (g *generator) writeOperation(w *bytes.Buffer, op operation, requestName string) {
+	// request
+	var params []*openapi3.Parameter
+	for _, ref := range op.Op.Parameters {
+		if p := ref.Value; p != nil && p.In != openapi3.ParameterInCookie { // the cookies are sent by the browser
+			params = append(params, p)
+		}
+	}
+	var bodyContentType string
+	var bodyMedia *openapi3.MediaType
+	if body := op.Op.RequestBody; body != nil && body.Value != nil {
+		bodyContentType, bodyMedia = oasutil.RequestBodyMedia(body.Value)
+	}
+
+	hasInput := len(params) > 0 || bodyMedia != nil
+	if hasInput {
+		fmt.Fprintf(w, "export interface %s {\n", requestName)
+		for _, p := range params {
+			writeTSDoc(w, "  ", p.Description, p.Deprecated)
+			optional := "?"
+			if p.Required {
+				optional = ""
+			}
+			fmt.Fprintf(w, "  %s%s: %s;\n", propertyName(p.Name), optional, g.typeExpr(p.Schema, "  "))
+		}
+		if bodyMedia != nil {
+			writeTSDoc(w, "  ", op.Op.RequestBody.Value.Description, false)
+			var typ string
+			switch bodyContentType {
+			case "application/json":
+				typ = g.typeExpr(bodyMedia.Schema, "  ")
+			case "multipart/form-data":
+				typ = "FormData"
+			case "application/x-www-form-urlencoded":
+				typ = "URLSearchParams"
+			default:
+				typ = "Blob | string"
+			}
+			fmt.Fprintf(w, "  body: %s;\n", typ)
+		}
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+
+	// response
+	outputType := "void"
+	parse := ""
+	if _, success := oasutil.SuccessResponse(op.Op); success != nil {
+		if media := success.Content.Get("application/json"); media != nil && media.Schema != nil {
+			outputType = g.typeExpr(media.Schema, "")
+			parse = fmt.Sprintf("(await res.json()) as %s", outputType)
+		} else if len(success.Content) > 0 {
+			outputType = "Blob"
+			parse = "await res.blob()"
+		}
+	}
+	errorType := g.errorType(op.Op)
+
+	// function
+	var doc []string
+	summary := op.Op.Summary
+	if summary == "" {
+		summary = op.Op.Description
+	}
+	if summary != "" {
+		doc = append(doc, strings.Split(strings.TrimSpace(summary), "\n")...)
+		doc = append(doc, "")
+	}
+	doc = append(doc, fmt.Sprintf("%s %s", op.Method, op.Path), "", fmt.Sprintf("@throws {ResponseError<%s>} if the response is not 2xx", errorType))
+	writeTSDoc(w, "", strings.Join(doc, "\n"), op.Op.Deprecated)
+
+	fnName := strings.ToLower(op.Name[:1]) + op.Name[1:]
+	fmt.Fprintf(w, "export async function %s(client: Client", fnName)
+	if hasInput {
+		fmt.Fprintf(w, ", input: %s", requestName)
+	}
+	fmt.Fprintf(w, ", init?: RequestInit): Promise<%s> {\n", outputType)
+
+	fmt.Fprintln(w, "  const query = new URLSearchParams();")
+	fmt.Fprintln(w, "  const headers: Record<string, string> = {};")
+	for _, p := range params {
+		access := "input." + p.Name
+		if propertyName(p.Name) != p.Name {
+			access = fmt.Sprintf("input[%s]", propertyName(p.Name))
+		}
+		var set string
+		switch p.In {
+		case openapi3.ParameterInQuery:
+			set = fmt.Sprintf("query.append(%s, String(%%s));", strconv.Quote(p.Name))
+		case openapi3.ParameterInHeader:
+			set = fmt.Sprintf("headers[%s] = String(%%s);", strconv.Quote(p.Name))
+		default:
+			continue
+		}
+		schema := oasutil.Lookup(g.doc, g.info, p.Schema)
+		switch {
+		case schema != nil && schema.Type == openapi3.TypeArray:
+			fmt.Fprintf(w, "  for (const v of %s ?? []) {\n    %s\n  }\n", access, fmt.Sprintf(set, "v"))
+		case !p.Required:
+			fmt.Fprintf(w, "  if (%s !== undefined && %s !== null) {\n    %s\n  }\n", access, access, fmt.Sprintf(set, access))
+		default:
+			fmt.Fprintf(w, "  %s\n", fmt.Sprintf(set, access))
+		}
+	}
+	body := "undefined"
+	if bodyMedia != nil {
+		switch bodyContentType {
+		case "application/json":
+			fmt.Fprintln(w, "  headers[\"Content-Type\"] = \"application/json\";")
+			body = "JSON.stringify(input.body)"
+		case "multipart/form-data", "application/x-www-form-urlencoded": // the content-type is set by fetch
+			body = "input.body"
+		default:
+			fmt.Fprintf(w, "  headers[\"Content-Type\"] = %s;\n", strconv.Quote(bodyContentType))
+			body = "input.body"
+		}
+	}
+
+	fmt.Fprintf(w, "  const res = await send(client, %s, %s, query, headers, %s, init);\n", strconv.Quote(op.Method), pathExpr(op.Path, params), body)
+	fmt.Fprintln(w, "  if (!res.ok) {")
+	fmt.Fprintf(w, "    throw await responseError<%s>(res);\n", errorType)
+	fmt.Fprintln(w, "  }")
+	if parse != "" {
+		fmt.Fprintf(w, "  return %s;\n", parse)
+	}
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+}
+
+// errorType returns the union of the body types of the error responses (4xx, 5xx and default).
+func (g *generator) errorType(op *openapi3.Operation) string {
+	var types []string
+	seen := map[string]bool{}
+	walknode.Response(op, func(ref *openapi3.ResponseRef, name string) {
+		if code, err := strconv.Atoi(name); (err != nil && name != "default") || (err == nil && code < 400) {
+			return
+		}
+		if ref.Value == nil {
+			return
+		}
+		if media := ref.Value.Content.Get("application/json"); media != nil && media.Schema != nil {
+			if typ := g.typeExpr(media.Schema, ""); !seen[typ] {
+				seen[typ] = true
+				types = append(types, typ)
+			}
+		}
+	})
+	if len(types) == 0 {
+		return "unknown"
+	}
+	return strings.Join(types, " | ")
+}
+
+// pathExpr returns the template literal of the path (e.g. `/pets/${encodeURIComponent(String(input.id))}`).
+func pathExpr(path string, params []*openapi3.Parameter) string {
+	declared := map[string]bool{}
+	for _, p := range params {
+		if p.In == openapi3.ParameterInPath {
+			declared[p.Name] = true
+		}
+	}
+
+	w := new(strings.Builder)
+	w.WriteString("`")
+	rest := path
+	for {
+		start := strings.Index(rest, "{")
+		end := strings.Index(rest, "}")
+		if start < 0 || end < start {
+			break
+		}
+		name := rest[start+1 : end]
+		w.WriteString(escapeTemplate(rest[:start]))
+		if declared[name] {
+			access := "input." + name
+			if propertyName(name) != name {
+				access = fmt.Sprintf("input[%s]", propertyName(name))
+			}
+			fmt.Fprintf(w, "${encodeURIComponent(String(%s))}", access)
+		} else {
+			w.WriteString(escapeTemplate(rest[start : end+1]))
+		}
+		rest = rest[end+1:]
+	}
+	w.WriteString(escapeTemplate(rest))
+	w.WriteString("`")
+	return w.String()
+}
+
+func escapeTemplate(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+}
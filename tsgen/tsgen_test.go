@@ -0,0 +1,202 @@
+package tsgen_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+	"github.com/podhmo/reflect-openapi/info"
+	"github.com/podhmo/reflect-openapi/tsgen"
+)
+
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// Pet is the pet
+type Pet struct {
+	Name    string   `json:"name" description:"name of pet"`
+	ID      int64    `json:"id"`
+	Kind    string   `json:"kind" openapi-override:"{'enum': ['cat', 'dog']}"`
+	Tag     string   `json:"tag,omitempty"`
+	Memo    *string  `json:"memo" openapi-override:"{'nullable': true}"`
+	Owner   *Owner   `json:"owner,omitempty"`
+	Tags    []string `json:"tags"`
+	Friends []Pet    `json:"friends,omitempty"`
+}
+
+type Owner struct {
+	Name string `json:"name"`
+}
+
+type ListPetsInput struct {
+	Tags      []string `json:"tags" in:"query"`
+	Limit     int32    `json:"limit,omitempty" in:"query"`
+	RequestID string   `json:"requestId" in:"header" header:"X-Request-ID"`
+}
+
+// ListPets returns all pets
+func ListPets(ctx context.Context, input ListPetsInput) ([]Pet, error) { return nil, nil }
+
+type FindPetInput struct {
+	ID int64 `json:"id" in:"path"`
+}
+
+// FindPet returns a pet
+func FindPet(ctx context.Context, input FindPetInput) (*Pet, error) { return nil, nil }
+
+type AddPetInput struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func AddPet(ctx context.Context, input AddPetInput) (reflectopenapi.Created[Pet], error) {
+	return reflectopenapi.Created[Pet]{}, nil
+}
+
+type DeletePetInput struct {
+	ID int64 `json:"id" in:"path"`
+}
+
+type DeletePetOutput struct {
+	_ struct{} `status:"204"`
+}
+
+func DeletePet(ctx context.Context, input DeletePetInput) (DeletePetOutput, error) {
+	return DeletePetOutput{}, nil
+}
+
+var ErrNotFound = fmt.Errorf("not found")
+
+func TestGenerate(t *testing.T) {
+	c := &reflectopenapi.Config{SkipValidation: true, Info: info.New(), DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.RegisterError(ErrNotFound, 404, "not found")
+		m.Get("/pets", ListPets)
+		m.Post("/pets", AddPet)
+		m.Get("/pets/{id}", FindPet).Errors(ErrNotFound)
+		m.Delete("/pets/{id}", DeletePet)
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tsgen.Generate(&buf, doc, c.Info, nil); err != nil {
+		t.Fatalf("tsgen.Generate(): unexpected error: %+v", err)
+	}
+	code := buf.String()
+
+	cases := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "header", want: "// Code generated by github.com/podhmo/reflect-openapi/tsgen; DO NOT EDIT.\n"},
+		{msg: "interface (the field order is the same as the go struct)", want: `export interface Pet {
+  /** name of pet */
+  name: string;
+  id: number;
+  kind: "cat" | "dog";
+  tag?: string;
+  memo?: string | null;
+  owner?: Owner;
+  tags: string[];
+  friends?: Pet[];
+}
+`},
+		{msg: "request", want: `export interface ListPetsRequest {
+  tags?: string[];
+  limit?: number;
+  "X-Request-ID"?: string;
+}
+`},
+		{msg: "request body", want: "  body: AddPetInput;\n"},
+		{msg: "function with TSDoc", want: `/**
+ * ListPets returns all pets
+ *
+ * GET /pets
+ *
+ * @throws {ResponseError<Error>} if the response is not 2xx
+ */
+export async function listPets(client: Client, input: ListPetsRequest, init?: RequestInit): Promise<Pet[]> {`},
+		{msg: "repeated query param", want: "  for (const v of input.tags ?? []) {\n    query.append(\"tags\", String(v));\n  }\n"},
+		{msg: "optional header param", want: "  if (input[\"X-Request-ID\"] !== undefined && input[\"X-Request-ID\"] !== null) {\n    headers[\"X-Request-ID\"] = String(input[\"X-Request-ID\"]);\n  }\n"},
+		{msg: "path param", want: "`/pets/${encodeURIComponent(String(input.id))}`"},
+		{msg: "json body", want: "JSON.stringify(input.body)"},
+		{msg: "status code of output", want: "export async function addPet(client: Client, input: AddPetRequest, init?: RequestInit): Promise<Pet> {"},
+		{msg: "no content", want: "export async function deletePet(client: Client, input: DeletePetRequest, init?: RequestInit): Promise<void> {"},
+		{msg: "parse response", want: "  return (await res.json()) as Pet;\n"},
+		{msg: "error response", want: "    throw await responseError<Error>(res);\n"},
+	}
+	for _, c := range cases {
+		if !strings.Contains(code, c.want) {
+			t.Errorf("%s: %q is not found in the generated code", c.msg, c.want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("generated code:\n%s", code)
+	}
+
+	t.Run("DisableClient", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := tsgen.Generate(&buf, doc, c.Info, &tsgen.Config{DisableClient: true}); err != nil {
+			t.Fatalf("tsgen.Generate(): unexpected error: %+v", err)
+		}
+		code := buf.String()
+		if !strings.Contains(code, "export interface Pet {") {
+			t.Errorf("the types are not found in the generated code")
+		}
+		if strings.Contains(code, "export async function") {
+			t.Errorf("the functions are found in the generated code, but DisableClient is true")
+		}
+	})
+}
+
+func TestGenerateAllOf(t *testing.T) {
+	base := openapi3.NewObjectSchema().WithProperty("id", openapi3.NewInt64Schema())
+	withRef := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	withRef.AllOf = openapi3.SchemaRefs{openapi3.NewSchemaRef("#/components/schemas/Base", nil)}
+	withInline := openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	withInline.AllOf = openapi3.SchemaRefs{
+		openapi3.NewSchemaRef("#/components/schemas/Base", nil),
+		openapi3.NewObjectSchema().WithProperty("tag", openapi3.NewStringSchema()).NewRef(),
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: "3.0.0",
+		Info:    &openapi3.Info{Title: "allOf", Version: "0.0.0"},
+		Paths:   openapi3.Paths{},
+		Components: &openapi3.Components{Schemas: openapi3.Schemas{
+			"Base":       base.NewRef(),
+			"WithRef":    withRef.NewRef(),
+			"WithInline": withInline.NewRef(),
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := tsgen.Generate(&buf, doc, nil, &tsgen.Config{DisableClient: true}); err != nil {
+		t.Fatalf("tsgen.Generate(): unexpected error: %+v", err)
+	}
+	code := buf.String()
+
+	cases := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "$ref base", want: "export interface WithRef extends Base {\n"},
+		{msg: "inline base (extends { ... } is invalid)", want: "export type WithInline = Base & {\n  tag?: string;\n} & {\n  name?: string;\n};\n"},
+	}
+	for _, c := range cases {
+		if !strings.Contains(code, c.want) {
+			t.Errorf("%s: %q is not found in the generated code", c.msg, c.want)
+		}
+	}
+	if t.Failed() {
+		t.Logf("generated code:\n%s", code)
+	}
+}
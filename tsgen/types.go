@@ -0,0 +1,246 @@
+package tsgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/podhmo/reflect-openapi/internal/oasutil"
+	"github.com/podhmo/reflect-openapi/walknode"
+)
+
+// reservedNames are the names declared in the runtime code (and the globals used by it).
+var reservedNames = []string{"Client", "ResponseError", "send", "responseError", "Blob", "FormData", "URLSearchParams", "Record", "Promise", "Response", "RequestInit"}
+
+// resolveNames decides the names of the types of components/schemas.
+func (g *generator) resolveNames() {
+	g.names = map[string]bool{}
+	for _, name := range reservedNames {
+		g.names[name] = true
+	}
+	g.componentNames = map[string]string{}
+	if g.doc.Components != nil {
+		walknode.Schema(g.doc, func(ref *openapi3.SchemaRef, name string) {
+			g.componentNames[name] = oasutil.UniqueName(g.names, tsName(name))
+		})
+	}
+}
+
+// typeExpr returns the typescript type of the schema (e.g. "Pet", "Pet[]", "Record<string, number>", "string | null").
+func (g *generator) typeExpr(ref *openapi3.SchemaRef, indent string) string {
+	schema := oasutil.Lookup(g.doc, g.info, ref)
+	if schema == nil {
+		return "unknown"
+	}
+
+	var typ string
+	if name, ok := g.componentNames[strings.TrimPrefix(ref.Ref, oasutil.ComponentPrefix)]; ok && ref.Ref != "" {
+		typ = name
+	} else {
+		typ = g.inlineTypeExpr(schema, indent)
+	}
+	if schema.Nullable && !strings.HasSuffix(typ, " | null") {
+		typ += " | null"
+	}
+	return typ
+}
+
+func (g *generator) inlineTypeExpr(schema *openapi3.Schema, indent string) string {
+	if len(schema.Enum) > 0 {
+		return enumExpr(schema.Enum)
+	}
+	switch {
+	case len(schema.OneOf) > 0:
+		return g.unionExpr(schema.OneOf, " | ", indent)
+	case len(schema.AnyOf) > 0:
+		return g.unionExpr(schema.AnyOf, " | ", indent)
+	case len(schema.AllOf) > 0 && len(schema.Properties) == 0:
+		return g.unionExpr(schema.AllOf, " & ", indent)
+	}
+
+	switch schema.Type {
+	case openapi3.TypeString:
+		return "string"
+	case openapi3.TypeInteger, openapi3.TypeNumber:
+		return "number"
+	case openapi3.TypeBoolean:
+		return "boolean"
+	case openapi3.TypeArray:
+		item := g.typeExpr(schema.Items, indent)
+		if strings.ContainsAny(item, "|&") {
+			item = "(" + item + ")"
+		}
+		return item + "[]"
+	}
+
+	if schema.AdditionalProperties.Schema != nil {
+		return fmt.Sprintf("Record<string, %s>", g.typeExpr(schema.AdditionalProperties.Schema, indent))
+	}
+	if len(schema.Properties) > 0 {
+		w := new(bytes.Buffer)
+		w.WriteString("{\n")
+		g.writeProperties(w, schema, indent+"  ")
+		w.WriteString(indent + "}")
+		typ := w.String()
+		if len(schema.AllOf) > 0 {
+			typ = g.unionExpr(schema.AllOf, " & ", indent) + " & " + typ
+		}
+		return typ
+	}
+	if schema.Type == openapi3.TypeObject {
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func (g *generator) unionExpr(refs openapi3.SchemaRefs, sep string, indent string) string {
+	types := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		typ := g.typeExpr(ref, indent)
+		if strings.ContainsAny(typ, "|&") {
+			typ = "(" + typ + ")"
+		}
+		types = append(types, typ)
+	}
+	return strings.Join(types, sep)
+}
+
+func enumExpr(values []interface{}) string {
+	literals := make([]string, 0, len(values))
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+		literals = append(literals, string(b))
+	}
+	return strings.Join(literals, " | ")
+}
+
+// writeProperties writes the properties of the object, the order is the same as the go struct (if info is available).
+func (g *generator) writeProperties(w *bytes.Buffer, schema *openapi3.Schema, indent string) {
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	for _, name := range oasutil.OrderedProperties(g.info, schema) {
+		prop := schema.Properties[name]
+		sub := oasutil.Lookup(g.doc, g.info, prop)
+		if sub == nil {
+			continue
+		}
+		writeTSDoc(w, indent, sub.Description, sub.Deprecated)
+		optional := ""
+		if !required[name] {
+			optional = "?"
+		}
+		readonly := ""
+		if sub.ReadOnly {
+			readonly = "readonly "
+		}
+		fmt.Fprintf(w, "%s%s%s%s: %s;\n", indent, readonly, propertyName(name), optional, g.typeExpr(prop, indent))
+	}
+}
+
+// writeComponents writes the types of components/schemas, the objects are emitted as the interfaces and the enums are emitted as the unions of the literals.
+func (g *generator) writeComponents(w *bytes.Buffer) {
+	if g.doc.Components == nil {
+		return
+	}
+	walknode.Schema(g.doc, func(ref *openapi3.SchemaRef, name string) {
+		schema := oasutil.Lookup(g.doc, g.info, ref)
+		if schema == nil {
+			return
+		}
+
+		writeTSDoc(w, "", schema.Description, schema.Deprecated)
+		typeName := g.componentNames[name]
+		if len(schema.Properties) > 0 && len(schema.Enum) == 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 && !schema.Nullable && extendable(schema.AllOf) {
+			fmt.Fprintf(w, "export interface %s", typeName)
+			if len(schema.AllOf) > 0 {
+				bases := make([]string, 0, len(schema.AllOf))
+				for _, ref := range schema.AllOf {
+					bases = append(bases, g.typeExpr(ref, ""))
+				}
+				fmt.Fprintf(w, " extends %s", strings.Join(bases, ", "))
+			}
+			fmt.Fprintln(w, " {")
+			g.writeProperties(w, schema, "  ")
+			fmt.Fprintln(w, "}")
+		} else {
+			fmt.Fprintf(w, "export type %s = %s;\n", typeName, g.typeExpr(&openapi3.SchemaRef{Value: schema}, ""))
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// extendable reports whether all of the bases can be written in the extends clause of the interface.
+// (the inline base (e.g. `extends { ... }`) is invalid, in this case the intersection type is used instead)
+func extendable(bases openapi3.SchemaRefs) bool {
+	for _, ref := range bases {
+		if ref.Ref == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func writeTSDoc(w *bytes.Buffer, indent string, description string, deprecated bool) {
+	var lines []string
+	if description = strings.TrimSpace(description); description != "" {
+		lines = strings.Split(description, "\n")
+	}
+	if deprecated {
+		lines = append(lines, "@deprecated")
+	}
+	switch len(lines) {
+	case 0:
+		return
+	case 1:
+		fmt.Fprintf(w, "%s/** %s */\n", indent, escapeComment(lines[0]))
+	default:
+		fmt.Fprintf(w, "%s/**\n", indent)
+		for _, line := range lines {
+			if line = strings.TrimRight(line, " \t"); line == "" {
+				fmt.Fprintf(w, "%s *\n", indent)
+			} else {
+				fmt.Fprintf(w, "%s * %s\n", indent, escapeComment(line))
+			}
+		}
+		fmt.Fprintf(w, "%s */\n", indent)
+	}
+}
+
+func escapeComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "*\\/")
+}
+
+var (
+	identifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	invalidRegex    = regexp.MustCompile(`[^A-Za-z0-9_$]+`)
+)
+
+// tsName returns the typescript identifier of the name in components/schemas (the name is kept as-is, if it is valid).
+func tsName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	name = invalidRegex.ReplaceAllString(name, "_")
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// propertyName returns the property name, quoted if it is not the identifier (e.g. "X-Request-ID").
+func propertyName(name string) string {
+	if identifierRegex.MatchString(name) {
+		return name
+	}
+	b, _ := json.Marshal(name)
+	return string(b)
+}
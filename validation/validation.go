@@ -0,0 +1,119 @@
+// Package validation provides the http middleware that validates the incoming requests with the openapi doc (built by reflectopenapi.Config.BuildDoc()).
+//
+// The parameters (path, query, header, cookie) and the request bodies are validated with the filter of kin-openapi (openapi3filter),
+// without any network access.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/podhmo/reflect-openapi/pkg/docrouter"
+)
+
+// Middleware validates the requests, before calling the next handler.
+type Middleware struct {
+	Router  routers.Router
+	Options *openapi3filter.Options // if nil, the authentication is skipped (it is the job of the other middleware)
+
+	// ErrorResponse builds the response body of the validation error, the returned value is encoded as JSON.
+	// e.g. func(code int, err error) interface{} { return Error{Code: int32(code), Message: err.Error()} }  // the type used as Config.DefaultError
+	ErrorResponse func(code int, err error) interface{}
+
+	RejectUnknownRoutes bool // if true, the requests that are not found in the doc are rejected with 404 or 405, default is passing through
+}
+
+// New creates the middleware, the doc is validated on building the router.
+// The servers of the doc are ignored and the requests are matched by the paths only
+// (if the paths are mounted under the prefix, please use http.StripPrefix()).
+func New(doc *openapi3.T) (*Middleware, error) {
+	router, err := docrouter.New(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &Middleware{Router: router}, nil
+}
+
+// Wrap returns the handler that validates the requests before calling next.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	options := m.Options
+	if options == nil {
+		options = &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := m.Router.FindRoute(r)
+		if err != nil {
+			if m.RejectUnknownRoutes {
+				m.writeError(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			m.writeError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *Middleware) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	code := StatusCode(err)
+	var body interface{}
+	if m.ErrorResponse != nil {
+		body = m.ErrorResponse(code, err)
+	} else {
+		body = map[string]interface{}{"code": code, "message": err.Error()}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[WARN]  validation: encode error response of %s %s: %+v", r.Method, r.URL.Path, err)
+	}
+}
+
+// StatusCode returns the status code of the error returned by the router or openapi3filter.ValidateRequest().
+func StatusCode(err error) int {
+	var routeErr *routers.RouteError
+	if errors.As(err, &routeErr) {
+		switch routeErr.Reason {
+		case routers.ErrMethodNotAllowed.Error():
+			return http.StatusMethodNotAllowed
+		default:
+			return http.StatusNotFound
+		}
+	}
+
+	var securityErr *openapi3filter.SecurityRequirementsError
+	if errors.As(err, &securityErr) {
+		return http.StatusUnauthorized
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.RequestBody != nil {
+		if strings.HasPrefix(requestErr.Reason, "header Content-Type has unexpected value") {
+			return http.StatusUnsupportedMediaType
+		}
+		var parseErr *openapi3filter.ParseError
+		if errors.As(requestErr.Err, &parseErr) && parseErr.Kind == openapi3filter.KindUnsupportedFormat {
+			return http.StatusUnsupportedMediaType
+		}
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,133 @@
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	reflectopenapi "github.com/podhmo/reflect-openapi"
+)
+
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+type Pet struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+type AddPetInput struct {
+	Name string `json:"name"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+func AddPet(input AddPetInput) (*Pet, error) { return nil, nil }
+
+type FindPetInput struct {
+	ID     int64  `json:"id" in:"path"`
+	Fields string `json:"fields" in:"query" required:"true"`
+	Limit  int32  `json:"limit,omitempty" in:"query"`
+}
+
+func FindPet(input FindPetInput) (*Pet, error) { return nil, nil }
+
+func TestMiddleware(t *testing.T) {
+	c := &reflectopenapi.Config{SkipValidation: true, DefaultError: Error{}}
+	doc, err := c.BuildDoc(context.Background(), func(m *reflectopenapi.Manager) {
+		m.Post("/pets", AddPet)
+		m.Get("/pets/{id}", FindPet)
+	})
+	if err != nil {
+		t.Fatalf("c.BuildDoc(): unexpected error: %+v", err)
+	}
+
+	mw, err := New(doc)
+	if err != nil {
+		t.Fatalf("New(): unexpected error: %+v", err)
+	}
+	mw.ErrorResponse = func(code int, err error) interface{} {
+		return Error{Code: int32(code), Message: "invalid request"}
+	}
+
+	handler := mw.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) // the body can be read again after validation
+		w.Write(body)
+	}))
+
+	cases := []struct {
+		msg         string
+		method      string
+		path        string
+		body        string
+		contentType string
+		reject      bool
+
+		code int
+		want string
+	}{
+		{msg: "ok", method: "POST", path: "/pets", body: `{"name": "foo"}`, code: 200, want: `{"name": "foo"}`},
+		{msg: "required field", method: "POST", path: "/pets", body: `{"tag": "foo"}`, code: 400},
+		{msg: "type mismatch", method: "POST", path: "/pets", body: `{"name": 1}`, code: 400},
+		{msg: "unexpected content-type", method: "POST", path: "/pets", body: `name=foo`, contentType: "application/x-www-form-urlencoded", code: 415},
+		{msg: "ok-params", method: "GET", path: "/pets/1?fields=name", code: 200},
+		{msg: "invalid path param", method: "GET", path: "/pets/foo?fields=name", code: 400},
+		{msg: "required query param", method: "GET", path: "/pets/1", code: 400},
+		{msg: "invalid query param", method: "GET", path: "/pets/1?fields=name&limit=foo", code: 400},
+		{msg: "unknown route", method: "GET", path: "/unknown", code: 404, want: "404 page not found\n"}, // passing through
+		{msg: "unknown route-rejected", method: "GET", path: "/unknown", reject: true, code: 404},
+		{msg: "method not allowed-rejected", method: "DELETE", path: "/pets", reject: true, code: 405},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			mw.RejectUnknownRoutes = c.reject
+			handler := handler
+			if !c.reject && c.code == 404 {
+				handler = mw.Wrap(http.NotFoundHandler())
+			}
+
+			var body io.Reader
+			if c.body != "" {
+				body = strings.NewReader(c.body)
+			}
+			req := httptest.NewRequest(c.method, c.path, body)
+			if c.body != "" {
+				contentType := c.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				req.Header.Set("Content-Type", contentType)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if want, got := c.code, rec.Code; want != got {
+				t.Errorf("%s %s: status code, want %d, but got %d (response=%s)", c.method, c.path, want, got, rec.Body.String())
+			}
+
+			if c.code == 200 || c.want != "" {
+				if diff := cmp.Diff(c.want, rec.Body.String()); diff != "" {
+					t.Errorf("%s %s: response mismatch (-want +got):\n%s", c.method, c.path, diff)
+				}
+				return
+			}
+
+			var got Error
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("%s %s: unexpected decode error: %+v", c.method, c.path, err)
+			}
+			if diff := cmp.Diff(Error{Code: int32(c.code), Message: "invalid request"}, got); diff != "" {
+				t.Errorf("%s %s: error response mismatch (-want +got):\n%s", c.method, c.path, diff)
+			}
+		})
+	}
+}
@@ -29,9 +29,17 @@ func NewVisitor(tagNameOption TagNameOption, resolver Resolver, selector Selecto
 		cache:            map[int]interface{}{},
 		defaultValues:    map[int]reflect.Value{},
 		interceptFuncMap: map[reflect.Type]func(*shape.Shape) *openapi3.Schema{},
-		Resolver:         resolver,
-		Selector:         selector,
-		Extractor:        extractor,
+		unionMap:         map[reflect.Type]*unionDef{},
+
+		requiredPolicyMap:   map[reflect.Type]RequiredPolicy{},
+		requestContentTypes: map[int]string{},
+		Resolver:            resolver,
+		Selector:            selector,
+		Extractor:           extractor,
+
+		discriminatorRefs: map[*openapi3.Discriminator]map[string]*openapi3.SchemaRef{},
+		visiting:          map[int]bool{},
+		recursive:         map[int]bool{},
 	}).Builtin()
 	transformer.IsRequired = transformer.isRequired
 	if t, ok := selector.(needTransformer); ok {
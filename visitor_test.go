@@ -3,6 +3,7 @@ package reflectopenapi_test
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -142,6 +143,41 @@ func TestVisitType(t *testing.T) {
 			}{},
 			Output: `{"type": "object", "properties": {"metadata": {"additionalProperties": {"type": "object", "properties": {"type": {"type": "string"}, "value": {"type": "string"}, "field": {"type": "string"}},"required": ["field","type","value"]}}}, "required": ["metadata"]}`,
 		},
+		{
+			Msg: "struct, for map[integer, primitive] field",
+			Input: struct {
+				Points map[int]string  `json:"points"`
+				Counts map[uint8]int64 `json:"counts"`
+			}{},
+			Output: `{"type": "object", "properties": {
+				"points": {"additionalProperties": {"type": "string"}, "x-go-key-type": "int", "x-propertyNames": {"pattern": "^-?[0-9]+$"}},
+				"counts": {"additionalProperties": {"type": "integer", "format": "int64"}, "x-go-key-type": "uint8", "x-propertyNames": {"pattern": "^[0-9]+$"}}
+			}, "required": ["points", "counts"]}`,
+		},
+		{
+			Msg: "struct, for map[encoding.TextMarshaler, primitive] field",
+			Input: struct {
+				Points map[TextKey]int `json:"points"`
+			}{},
+			Output: `{"type": "object", "properties": {"points": {"additionalProperties": {"type": "integer"}, "x-go-key-type": "reflectopenapi_test.TextKey"}}, "required": ["points"]}`,
+		},
+		// encoding.TextMarshaler
+		{
+			Msg: "struct, for encoding.TextMarshaler field",
+			Input: struct {
+				Key  TextKey   `json:"key"`
+				Keys []TextKey `json:"keys"`
+			}{},
+			Output: `{"type": "object", "properties": {"key": {"type": "string"}, "keys": {"type": "array", "items": {"type": "string"}}}, "required": ["key", "keys"]}`,
+		},
+		{
+			Msg: "struct, for encoding.TextMarshaler fields with description",
+			Input: struct {
+				Owner  TextID `json:"owner" description:"owner"`
+				Author TextID `json:"author"`
+			}{},
+			Output: `{"type": "object", "properties": {"owner": {"type": "string", "description": "owner"}, "author": {"type": "string"}}, "required": ["owner", "author"]}`,
+		},
 		// interface
 		{
 			Msg: "struct, for empty interface field",
@@ -457,6 +493,14 @@ func TestVisitFunc(t *testing.T) {
 	}
 }
 
+type TextKey struct{ X, Y int }
+
+func (k TextKey) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("%d,%d", k.X, k.Y)), nil }
+
+type TextID int
+
+func (id TextID) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("id-%d", id)), nil }
+
 type EmbeddedParametersInput struct {
 	Name string `json:"name"`
 	EmbeddedParametersInputInner
@@ -719,3 +763,174 @@ func TestIsRequiredFunction(t *testing.T) {
 		}
 	})
 }
+
+type ForConstraint struct {
+	Name     string            `json:"name,omitempty" validate:"required,min=1,max=32"`
+	Age      int               `json:"age" validate:"gte=0,lt=150"`
+	Email    string            `json:"email" validate:"email"`
+	Role     string            `json:"role" validate:"oneof=admin 'normal user'"`
+	Level    int               `json:"level" validate:"oneof=1 2 3"`
+	Tags     []string          `json:"tags" validate:"max=3,dive,len=4"`
+	Labels   map[string]string `json:"labels" validate:"dive,keys,alpha,endkeys,uuid"`
+	Code     string            `json:"code" validate:"alpha|numeric"`
+	Nickname *string           `json:"nickname,omitempty"`
+}
+
+type ForConstraintInput struct {
+	ID     string `json:"id" in:"path" validate:"uuid"`
+	Limit  int    `json:"limit" in:"query" validate:"required,gt=0,max=100"`
+	Cursor string `json:"cursor" in:"query"`
+}
+
+func TestConstraintExtractor(t *testing.T) {
+	v := newVisitorDefault(&reflectopenapi.NoRefResolver{})
+	v.Transformer.ConstraintExtractor = &reflectopenapi.ValidatorConstraintExtractor{}
+
+	t.Run("struct", func(t *testing.T) {
+		got := v.VisitType(v.Extractor.Extract(ForConstraint{}))
+		want := `
+{
+	"type": "object",
+	"title": "ForConstraint",
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "maxLength": 32},
+		"age": {"type": "integer", "minimum": 0, "maximum": 150, "exclusiveMaximum": true},
+		"email": {"type": "string", "format": "email"},
+		"role": {"type": "string", "enum": ["admin", "normal user"]},
+		"level": {"type": "integer", "enum": [1, 2, 3]},
+		"tags": {"type": "array", "maxItems": 3, "items": {"type": "string", "minLength": 4, "maxLength": 4}},
+		"labels": {"additionalProperties": {"type": "string", "format": "uuid"}},
+		"code": {"type": "string"},
+		"nickname": {"type": "string"}
+	},
+	"required": ["name", "age", "email", "role", "level", "tags", "labels", "code"]
+}`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(got).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		got := v.VisitFunc(v.Extractor.Extract(func(ForConstraintInput) {}))
+		want := `
+[
+	{"in": "path", "name": "id", "required": true, "schema": {"type": "string", "format": "uuid"}},
+	{"in": "query", "name": "limit", "required": true, "schema": {"type": "integer", "minimum": 0, "exclusiveMinimum": true, "maximum": 100}},
+	{"in": "query", "name": "cursor", "schema": {"type": "string"}}
+]`
+		if err := jsonequal.NoDiff(
+			jsonequal.FromString(want).Named("want"),
+			jsonequal.From(got.Parameters).Named("got"),
+		); err != nil {
+			t.Errorf("%+v", err)
+		}
+	})
+}
+
+type ForRequiredPolicy struct {
+	Plain     string   `json:"plain"`
+	OmitEmpty string   `json:"omitEmpty,omitempty"`
+	Pointer   *string  `json:"pointer"`
+	Tagged    *string  `json:"tagged,omitempty" required:"true"`
+	Validated string   `json:"validated,omitempty" validate:"required"`
+	Spaced    string   `json:"spaced,omitempty" validate:"min=1, required"`
+	ItemsOnly []string `json:"itemsOnly,omitempty" validate:"omitempty, dive, required"` // required for the items
+
+	Query        string  `json:"query" in:"query"`
+	PointerQuery *string `json:"pointerQuery" in:"query"`
+	TaggedQuery  string  `json:"taggedQuery,omitempty" in:"query" required:"true"`
+}
+
+func TestRequiredPolicy(t *testing.T) {
+	cases := []struct {
+		msg      string
+		policy   reflectopenapi.RequiredPolicy
+		required []string // properties
+		params   []string // required parameters
+	}{
+		{msg: "default", policy: nil,
+			required: []string{"plain", "tagged"}, params: []string{"taggedQuery"}},
+		{msg: "omitempty", policy: &reflectopenapi.OmitEmptyRequiredPolicy{},
+			required: []string{"plain", "pointer", "tagged"}, params: []string{"query", "pointerQuery", "taggedQuery"}},
+		{msg: "pointer", policy: &reflectopenapi.PointerRequiredPolicy{},
+			required: []string{"plain", "omitEmpty", "tagged", "validated", "spaced", "itemsOnly"}, params: []string{"query", "taggedQuery"}},
+		{msg: "tag", policy: &reflectopenapi.TagRequiredPolicy{},
+			required: []string{"tagged"}, params: []string{"taggedQuery"}},
+		{msg: "validator", policy: &reflectopenapi.ValidatorRequiredPolicy{},
+			required: []string{"tagged", "validated", "spaced"}, params: []string{"taggedQuery"}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			v := newVisitorDefault(&reflectopenapi.NoRefResolver{})
+			v.Transformer.RequiredPolicy = c.policy
+
+			op := v.VisitFunc(v.Extractor.Extract(func(ForRequiredPolicy) {}))
+			schema := op.RequestBody.Value.Content.Get("application/json").Schema.Value
+			var params []string
+			for _, p := range op.Parameters {
+				if p.Value.Required {
+					params = append(params, p.Value.Name)
+				}
+			}
+
+			if err := jsonequal.NoDiff(
+				jsonequal.From(map[string]interface{}{"required": c.required, "params": c.params}).Named("want"),
+				jsonequal.From(map[string]interface{}{"required": schema.Required, "params": params}).Named("got"),
+			); err != nil {
+				t.Errorf("%+v", err)
+			}
+		})
+	}
+}
+
+func funcForRequiredPolicy(x int, memo string, pretty *bool) []int {
+	return nil
+}
+
+func TestRequiredPolicyMergeParams(t *testing.T) {
+	cases := []struct {
+		msg      string
+		policy   reflectopenapi.RequiredPolicy
+		required []string // properties
+		params   []string // required parameters
+	}{
+		{msg: "default", policy: nil,
+			required: []string{"x", "memo"}, params: nil},
+		{msg: "tag", policy: &reflectopenapi.TagRequiredPolicy{},
+			required: nil, params: nil},
+		{msg: "func", policy: reflectopenapi.RequiredPolicyFunc(func(f reflectopenapi.RequiredField) bool { return f.Name != "memo" }),
+			required: []string{"x"}, params: []string{"pretty"}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.msg, func(t *testing.T) {
+			v := newVisitor(&reflectopenapi.NoRefResolver{}, &struct {
+				reflectopenapi.MergeParamsInputSelector
+				reflectopenapi.FirstParamOutputSelector
+			}{}, nil)
+			v.Transformer.RequiredPolicy = c.policy
+
+			op := v.VisitFunc(v.Extractor.Extract(funcForRequiredPolicy))
+			schema := op.RequestBody.Value.Content.Get("application/json").Schema.Value
+			var params []string
+			for _, p := range op.Parameters {
+				if p.Value.Required {
+					params = append(params, p.Value.Name)
+				}
+			}
+
+			if err := jsonequal.NoDiff(
+				jsonequal.From(map[string]interface{}{"required": c.required, "params": c.params}).Named("want"),
+				jsonequal.From(map[string]interface{}{"required": schema.Required, "params": params}).Named("got"),
+			); err != nil {
+				t.Errorf("%+v", err)
+			}
+		})
+	}
+}